@@ -0,0 +1,201 @@
+package llog
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AccessFormat is the format of an access log
+type AccessFormat int
+
+const (
+	// AccessCombined is the Apache combined log format:
+	//	127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /index.html HTTP/1.1" 200 2326 "http://example.com/" "Mozilla/5.0"
+	AccessCombined AccessFormat = iota
+	// AccessW3C is the W3C extended log format, with the fields:
+	//	date time c-ip cs-username cs-method cs-uri-stem cs-uri-query sc-status sc-bytes time-taken cs(User-Agent) cs(Referer)
+	AccessW3C
+)
+
+// w3cFields are the fields written in the W3C extended log format
+const w3cFields = "date time c-ip cs-username cs-method cs-uri-stem cs-uri-query sc-status sc-bytes time-taken cs(User-Agent) cs(Referer)"
+
+// AccessLog is a HTTP middleware writing an access log, in a format read
+// by log analysis tools such as GoAccess and AWStats, to a separate file.
+// The access log file is wrapped independently of the log file, with a
+// backup when it is more than MaxSizeKB, and synced according to the
+// sync policy, see SetSyncPolicy. Access log files are never encrypted.
+//
+//	access := &llog.AccessLog{FileName: "access.log", MaxSizeKB: 10240}
+//	defer access.Close()
+//	http.ListenAndServe(":8080", access.Handler(mux))
+type AccessLog struct {
+	// FileName is the name of the access log file
+	FileName string
+	// MaxSizeKB is the size of the access log file that makes it wrap.
+	// Default is 1024.
+	MaxSizeKB int
+	// Format of the access log. Default is AccessCombined.
+	Format AccessFormat
+
+	once sync.Once
+	file *logFile // nil if the file couldn't be opened
+}
+
+// Handler returns a handler calling next and writing an entry to the
+// access log for each request. If the access log file can't be opened
+// the error is reported to the error handler, see SetErrorHandler, and
+// no entries are written.
+func (a *AccessLog) Handler(next http.Handler) http.Handler {
+	a.once.Do(a.open)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := now()
+		rec := &accessRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if a.file == nil {
+			return
+		}
+		var b []byte
+		if a.Format == AccessW3C {
+			b = appendW3C(b, r, rec, start)
+		} else {
+			b = appendCombined(b, r, rec, start)
+		}
+		a.file.Write(b) // Errors handled by the fail mode
+		a.file.entryWritten(LvlInfo)
+	})
+}
+
+// Close flushes, syncs and closes the access log file.
+func (a *AccessLog) Close() error {
+	a.once.Do(func() {}) // Don't open after close
+	if a.file == nil {
+		return nil
+	}
+	return closeLogFile(a.file)
+}
+
+// open opens the access log file.
+func (a *AccessLog) open() {
+	if a.MaxSizeKB <= 0 {
+		a.MaxSizeKB = 1024
+	}
+	file, err := openLogFile(a.FileName, a.MaxSizeKB)
+	if err != nil {
+		reportError(fmt.Errorf("llog: unable to open access log: %w", err))
+		return
+	}
+	file.aead, file.format, file.metadata = nil, FormatText, false
+	if a.Format == AccessW3C {
+		file.setHeader(func() string {
+			return "#Version: 1.0\n#Date: " + now().UTC().Format("2006-01-02 15:04:05") +
+				"\n#Fields: " + w3cFields
+		})
+	}
+	a.file = file
+}
+
+// accessRecorder records the status and size of a response
+type accessRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func (rec *accessRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *accessRecorder) Write(p []byte) (int, error) {
+	n, err := rec.ResponseWriter.Write(p)
+	rec.size += n
+	return n, err
+}
+
+// Flush flushes the response, if supported, so that streaming handlers
+// such as TailHandler work behind the middleware.
+func (rec *accessRecorder) Flush() {
+	if flusher, ok := rec.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the wrapped response writer, for http.ResponseController.
+func (rec *accessRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// appendCombined appends an entry in the Apache combined log format.
+func appendCombined(b []byte, r *http.Request, rec *accessRecorder, start time.Time) []byte {
+	b = append(b, remoteHost(r)...)
+	b = append(b, " - "...)
+	b = append(b, accessUser(r)...)
+	b = start.AppendFormat(append(b, " ["...), "02/Jan/2006:15:04:05 -0700")
+	b = strconv.AppendQuote(append(b, "] "...), r.Method+" "+r.RequestURI+" "+r.Proto)
+	b = strconv.AppendInt(append(b, ' '), int64(rec.status), 10)
+	b = append(b, ' ')
+	if rec.size > 0 {
+		b = strconv.AppendInt(b, int64(rec.size), 10)
+	} else {
+		b = append(b, '-')
+	}
+	b = strconv.AppendQuote(append(b, ' '), orDash(r.Referer()))
+	b = strconv.AppendQuote(append(b, ' '), orDash(r.UserAgent()))
+	return append(b, '\n')
+}
+
+// appendW3C appends an entry in the W3C extended log format, with the
+// fields in w3cFields.
+func appendW3C(b []byte, r *http.Request, rec *accessRecorder, start time.Time) []byte {
+	b = start.UTC().AppendFormat(b, "2006-01-02 15:04:05")
+	for _, value := range []string{remoteHost(r), accessUser(r), r.Method, r.URL.Path, r.URL.RawQuery} {
+		b = append(append(b, ' '), w3cValue(value)...)
+	}
+	b = strconv.AppendInt(append(b, ' '), int64(rec.status), 10)
+	b = strconv.AppendInt(append(b, ' '), int64(rec.size), 10)
+	b = strconv.AppendFloat(append(b, ' '), now().Sub(start).Seconds(), 'f', 3, 64)
+	b = append(append(b, ' '), w3cValue(r.UserAgent())...)
+	b = append(append(b, ' '), w3cValue(r.Referer())...)
+	return append(b, '\n')
+}
+
+// remoteHost returns the host of the client.
+func remoteHost(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// accessUser returns the basic authentication user or "-".
+func accessUser(r *http.Request) string {
+	if user, _, ok := r.BasicAuth(); ok && user != "" {
+		return strings.ReplaceAll(user, " ", "_")
+	}
+	return "-"
+}
+
+// orDash returns s or "-" if s is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// w3cValue returns a value in the W3C extended log format, where spaces
+// are written as '+' and empty values as "-".
+func w3cValue(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r < ' ' {
+			return '+'
+		}
+		return r
+	}, orDash(s))
+}
@@ -0,0 +1,59 @@
+// Unit tests for access
+package llog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func serveAccess(t *testing.T, format AccessFormat, fileName string) string {
+	os.Remove(fileName)
+	t.Cleanup(func() { os.Remove(fileName) })
+	access := &AccessLog{FileName: fileName, Format: format}
+	handler := access.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("hello"))
+	}))
+
+	r := httptest.NewRequest("GET", "/index.html?a=1", nil)
+	r.RemoteAddr = "10.0.0.1:51234"
+	r.Header.Set("User-Agent", "Mozilla/5.0 (X11)")
+	r.Header.Set("Referer", "http://example.com/")
+	r.SetBasicAuth("frank", "secret")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/missing", nil))
+	if err := access.Close(); err != nil {
+		t.Fatalf("Unable to close access log. Reason: %s", err)
+	}
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("Access log not written. Reason: %s", err)
+	}
+	return string(content)
+}
+
+func TestAccessLogCombined(t *testing.T) {
+	lines := strings.Split(serveAccess(t, AccessCombined, "accesscombined.log"), "\n")
+	first := regexp.MustCompile(`^10\.0\.0\.1 - frank \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /index.html\?a=1 HTTP/1.1" 200 5 "http://example.com/" "Mozilla/5.0 \(X11\)"$`)
+	second := regexp.MustCompile(`^192\.0\.2\.1 - - \[.*\] "POST /missing HTTP/1.1" 404 19 "-" "-"$`)
+	if len(lines) != 3 || !first.MatchString(lines[0]) || !second.MatchString(lines[1]) {
+		t.Fatalf("Combined access log not correct:\n%s", strings.Join(lines, "\n"))
+	}
+}
+
+func TestAccessLogW3C(t *testing.T) {
+	lines := strings.Split(serveAccess(t, AccessW3C, "accessw3c.log"), "\n")
+	first := regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2} 10\.0\.0\.1 frank GET /index.html a=1 200 5 \d+\.\d{3} Mozilla/5.0\+\(X11\) http://example.com/$`)
+	if len(lines) != 6 || lines[0] != "#Version: 1.0" || !strings.HasPrefix(lines[1], "#Date: ") ||
+		lines[2] != "#Fields: "+w3cFields || !first.MatchString(lines[3]) ||
+		!strings.Contains(lines[4], " - POST /missing - 404 19 ") {
+		t.Fatalf("W3C access log not correct:\n%s", strings.Join(lines, "\n"))
+	}
+}
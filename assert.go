@@ -0,0 +1,53 @@
+package llog
+
+import "fmt"
+
+// Must does nothing if err is nil. Otherwise it writes a log on panic
+// level with the message and err, flush the log and calls panic() with
+// an error wrapping err, as PanicErr. Must panics even if the panic
+// level is not written.
+func Must(err error, format string, v ...interface{}) {
+	globLogger.must(err, format, v)
+}
+
+// Check does nothing and returns false if err is nil. Otherwise it
+// writes a log on error level with the message and err and returns true.
+func Check(err error, format string, v ...interface{}) bool {
+	return globLogger.check(err, format, v)
+}
+
+// Must as the package function Must, with the worker tag of the logger.
+func (l *Logger) Must(err error, format string, v ...interface{}) {
+	l.must(err, format, v)
+}
+
+// Check as the package function Check, with the worker tag of the logger.
+func (l *Logger) Check(err error, format string, v ...interface{}) bool {
+	return l.check(err, format, v)
+}
+
+// must is called by Must with the same call depth as loglevel.
+func (l *Logger) must(err error, format string, v []interface{}) {
+	if err == nil {
+		return
+	}
+	err = fmt.Errorf("%s: %w", appendMessage(nil, msgPrintf, format, v), err)
+	if LvlPanic >= levelSet() {
+		l.output(3, LvlPanic, msgPlain, err.Error(), nil)
+	}
+	syncLog()
+	panicked(err.Error())
+	panic(err)
+}
+
+// check is called by Check with the same call depth as loglevel.
+func (l *Logger) check(err error, format string, v []interface{}) bool {
+	if err == nil {
+		return false
+	}
+	if level := l.eventLevel(LvlError); level >= levelSet() {
+		msg := appendMessage(nil, msgPrintf, format, v)
+		l.output(3, level, msgPlain, string(msg)+": "+err.Error(), nil)
+	}
+	return true
+}
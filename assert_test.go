@@ -0,0 +1,62 @@
+// Unit tests for assert
+package llog
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMust(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+
+	Must(nil, "not written")
+	cause := errors.New("disk full")
+	var r interface{}
+	func() {
+		defer func() { r = recover() }()
+		Must(cause, "unable to write %s", "state")
+	}()
+	if err, ok := r.(error); !ok || !errors.Is(err, cause) {
+		t.Fatalf("Error not preserved in panic: %#v", r)
+	}
+	if !strings.HasSuffix(buffer.String(), "assert_test.go:24: PANIC - unable to write state: disk full\n") ||
+		strings.Contains(buffer.String(), "not written") {
+		t.Fatalf("Must not logged correctly: %s", buffer.String())
+	}
+
+	// Panics also if panic level is not written
+	SetLevel(LvlAudit)
+	defer SetLevel(LvlInfo)
+	r = nil
+	func() {
+		defer func() { r = recover() }()
+		Must(cause, "filtered")
+	}()
+	if r == nil || strings.Contains(buffer.String(), "filtered") {
+		t.Fatalf("Must shall panic without logging when filtered: %s", buffer.String())
+	}
+}
+
+func TestCheck(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+
+	if Check(nil, "not written") {
+		t.Fatal("Check returned true for nil error")
+	}
+	if !WithWorker("w1").Check(errors.New("timeout"), "unable to connect to %s", "db") {
+		t.Fatal("Check returned false for error")
+	}
+	if !strings.HasSuffix(buffer.String(), "assert_test.go:56: [w1] ERROR - unable to connect to db: timeout\n") {
+		t.Fatalf("Check not logged correctly: %s", buffer.String())
+	}
+}
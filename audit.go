@@ -0,0 +1,49 @@
+package llog
+
+import "log"
+
+// globAuditFile is the file where audit output goes or nil if audit
+// entries are written to the same output as the other log entries
+var globAuditFile *logFile
+
+// globAuditLogger is the logger used for globAuditFile
+var globAuditLogger *log.Logger
+
+// SetAuditFile writes audit entries to a separate file instead of the
+// output used by the other log levels. The audit file is wrapped
+// independently of the ordinary log file, with a backup when it is more
+// than maxSizeKB. If an error occurs the current audit output will be kept.
+func SetAuditFile(fileName string, maxSizeKB int) error {
+	file, err := openLogFile(fileName, maxSizeKB)
+	if err != nil {
+		return err
+	}
+	return setAuditFile(file)
+}
+
+// setAuditFile makes file the audit file.
+func setAuditFile(file *logFile) error {
+	file.format = FormatText // Audit files are always text
+
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	if globAuditKey != nil {
+		if err := file.startChain(globAuditKey); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if globAuditFile != nil {
+		globAuditFile.Close()
+	}
+	globAuditFile = file
+	globAuditLogger = log.New(file, log.Prefix(), log.Flags())
+	updateOutput()
+	return nil
+}
+
+// Audit writes a log on audit level. Audit entries are always written,
+// regardless of the level set with SetLevel.
+func Audit(format string, v ...interface{}) {
+	globLogger.output(2, LvlAudit, msgPrintf, format, v)
+}
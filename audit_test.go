@@ -0,0 +1,127 @@
+// Unit tests for audit logging
+package llog
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAuditIgnoresLevel(t *testing.T) {
+	SetLevel(LvlPanic)
+	defer SetLevel(LvlInfo)
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	Info("not an audit")
+	Audit("user %s logged in", "joe")
+	result := buffer.String()
+	if strings.Contains(result, "not an audit") {
+		t.Fatalf("Info shall not be in log! Result:\n%s", result)
+	}
+	if !strings.Contains(result, "AUDIT - user joe logged in") {
+		t.Fatalf("Audit entry shall always be logged! Result:\n%s", result)
+	}
+	if !strings.Contains(result, "audit_test.go") {
+		t.Fatalf("The filename is not logged")
+	}
+}
+
+func TestSetAuditFile(t *testing.T) {
+	auditFileName := "auditlog.txt"
+	os.Remove(auditFileName)
+	SetLevel(LvlInfo)
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	err := SetAuditFile(auditFileName, 100)
+	if err != nil {
+		t.Fatalf("Unable to audit log to file. Reason: %s", err)
+	}
+	Info("an info")
+	Audit("an audit")
+
+	if strings.Contains(buffer.String(), "an audit") {
+		t.Fatalf("Audit entry shall not be in ordinary log")
+	}
+	content, _ := os.ReadFile(auditFileName)
+	if !strings.Contains(string(content), "AUDIT - an audit") {
+		t.Fatalf("Audit entry missing in audit file:\n%s", content)
+	}
+	if strings.Contains(string(content), "an info") {
+		t.Fatalf("Info entry shall not be in audit file")
+	}
+
+	// Cleanup
+	globAuditFile.Close()
+	globAuditFile = nil
+	globAuditLogger = nil
+	updateOutput()
+	os.Remove(auditFileName)
+}
+
+func TestSetInvalidAuditFile(t *testing.T) {
+	err := SetAuditFile("thispathdontexit/audit.txt", 100)
+	if err == nil {
+		t.Fatalf("Invalid file shall give an error")
+	}
+}
+
+func TestAuditChain(t *testing.T) {
+	auditFileName := "chainlog.txt"
+	key := []byte("secret")
+	os.Remove(auditFileName)
+	os.Remove(auditFileName + ".1")
+	SetAuditChain(key)
+	defer SetAuditChain(nil)
+	err := SetAuditFile(auditFileName, 2) // Max size 2 KB
+	if err != nil {
+		t.Fatalf("Unable to audit log to file. Reason: %s", err)
+	}
+	for i := 0; i < 100; i++ {
+		Audit("entry %d\nsecond line", i)
+	}
+	globAuditFile.Close()
+	if !fileExist(auditFileName + ".1") {
+		t.Fatalf("Audit file was never wrapped")
+	}
+
+	// Continue chain in existing file
+	err = SetAuditFile(auditFileName, 2)
+	if err != nil {
+		t.Fatalf("Unable to audit log to file. Reason: %s", err)
+	}
+	Audit("continued")
+	globAuditFile.Close()
+	for _, fileName := range []string{auditFileName, auditFileName + ".1"} {
+		if err := VerifyAudit(fileName, key); err != nil {
+			t.Fatalf("Verification failed: %s", err)
+		}
+	}
+	if err := VerifyAudit(auditFileName, []byte("wrong")); err == nil {
+		t.Fatalf("Verification with wrong key shall fail")
+	}
+
+	// Modify an entry
+	content, _ := os.ReadFile(auditFileName)
+	lines := strings.Split(string(content), "\n")
+	modified := strings.Replace(string(content), "continued", "changed", 1)
+	os.WriteFile(auditFileName, []byte(modified), 0666)
+	if err := VerifyAudit(auditFileName, key); err == nil {
+		t.Fatalf("Modified entry shall be detected")
+	}
+
+	// Remove first entry
+	truncated := strings.Join(lines[1:], "\n")
+	os.WriteFile(auditFileName, []byte(truncated), 0666)
+	if err := VerifyAudit(auditFileName, key); err == nil {
+		t.Fatalf("Removed first entry shall be detected")
+	}
+
+	// Cleanup
+	globAuditFile = nil
+	globAuditLogger = nil
+	updateOutput()
+	os.Remove(auditFileName)
+	os.Remove(auditFileName + ".1")
+}
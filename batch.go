@@ -0,0 +1,332 @@
+package llog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// BatchOptions configure how a sink sending entries to a log service
+// batches the entries. Zero values give the defaults.
+type BatchOptions struct {
+	// Size is the max number of entries in a batch. Default is 100.
+	Size int
+	// Interval is the max time an entry is queued before it is sent.
+	// Default is 1 second.
+	Interval time.Duration
+	// QueueSize is the max number of queued entries, which bounds the
+	// memory used when the log service is slow or unreachable. Dropped
+	// entries are counted, see Stats. Default is 10000.
+	QueueSize int
+	// Saturation decides what happens when an entry is written and the
+	// queue is full. Default is DropNewest.
+	Saturation Saturation
+	// MaxWait is the max time a write waits for room in the queue with
+	// the Block policy, before the entry is dropped. Default is 100 ms.
+	// Loggers can wait shorter, see WithMaxWait.
+	MaxWait time.Duration
+	// Retries is the number of times a batch that couldn't be sent is
+	// retried, with exponential backoff. Default is 3.
+	Retries int
+	// Fallback is the name of a file to which batches that couldn't be
+	// sent are appended, or empty to drop such batches.
+	Fallback string
+}
+
+// Saturation is what a sink does when an entry is written and its queue
+// is full, see BatchOptions.
+type Saturation int
+
+const (
+	// DropNewest drops the entry written (default)
+	DropNewest Saturation = iota
+	// DropLowest drops the oldest queued entry of the lowest level, if
+	// lower than the level of the entry written, otherwise the entry
+	// written, so that errors are kept when debug entries flood the
+	// queue
+	DropLowest
+	// Block waits for room in the queue, at most MaxWait, before the
+	// entry written is dropped. Writes that wait are counted, see Stats.
+	// The wait is also ended when the context of the logger is done, see
+	// WithContext and WithMaxWait.
+	Block
+)
+
+// entryWait is how long writing an entry may block, see WithMaxWait
+type entryWait struct {
+	ctx     context.Context // context of the logger or nil
+	maxWait time.Duration   // max wait, < 0 not at all, 0 MaxWait of the sink
+}
+
+// WithMaxWait returns a logger whose entries wait at most maxWait for
+// room in the queue of sinks with the Block policy, instead of the
+// MaxWait of the sinks, see BatchOptions. A maxWait of 0 or less never
+// waits, so that logging never stalls for example request handling.
+func WithMaxWait(maxWait time.Duration) *Logger {
+	return globLogger.WithMaxWait(maxWait)
+}
+
+// WithMaxWait returns a copy of the logger whose entries wait at most
+// maxWait for room in the queue of sinks with the Block policy. Entries
+// of a logger with a context, see WithContext, also stop waiting when
+// the context is done, for example when the deadline of a request has
+// passed:
+//
+//	logger := llog.WithContext(r.Context()).WithMaxWait(10 * time.Millisecond)
+func (l *Logger) WithMaxWait(maxWait time.Duration) *Logger {
+	c := *l
+	c.maxWait = maxWait
+	if maxWait <= 0 {
+		c.maxWait = -1
+	}
+	return &c
+}
+
+// batchRetryDelay is the delay before the first retry of a batch. The
+// delay is doubled for each retry.
+var batchRetryDelay = 500 * time.Millisecond
+
+// errSinkClosed is returned when writing to a closed sink
+var errSinkClosed = errors.New("llog: sink closed")
+
+// batcher queues entries and sends them in batches in the background.
+type batcher struct {
+	options  BatchOptions
+	send     func(batch []Entry) (sent int, err error)
+	done     chan struct{} // closed when all batches are sent
+	ready    chan struct{} // signaled when entries are queued or closed
+	space    chan struct{} // signaled when entries are taken from queue
+	mutex    sync.Mutex    // protects all below
+	started  bool
+	queue    []Entry
+	closed   bool
+	dropping bool // true if the queue is full
+}
+
+// start starts sending batches with send, which returns the number of
+// entries sent, from the beginning of the batch, also if it fails.
+func (b *batcher) start(options BatchOptions, send func(batch []Entry) (sent int, err error)) {
+	if options.Size <= 0 {
+		options.Size = 100
+	}
+	if options.Interval <= 0 {
+		options.Interval = time.Second
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = 10000
+	}
+	if options.MaxWait <= 0 {
+		options.MaxWait = 100 * time.Millisecond
+	}
+	if options.Retries <= 0 {
+		options.Retries = 3
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.closed {
+		return
+	}
+	b.options = options
+	b.send = send
+	b.started = true
+	b.done = make(chan struct{})
+	b.ready = make(chan struct{}, 1)
+	b.space = make(chan struct{}, 1)
+	go b.run()
+}
+
+// add queues an entry. If the queue is full the entry, or a queued
+// entry, is dropped as decided by the saturation policy.
+func (b *batcher) add(e Entry) error {
+	var timeout <-chan time.Time
+	var done <-chan struct{} // the context of the logger is done
+	wait := e.wait
+	e.wait = entryWait{} // Not kept in the queue
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for {
+		if b.closed || !b.started {
+			return errSinkClosed
+		}
+		if len(b.queue) < b.options.QueueSize {
+			b.queue = append(b.queue, e)
+			b.dropping = false
+			signal(b.ready)
+			return nil
+		}
+		switch b.options.Saturation {
+		case DropLowest:
+			if i := lowestBelow(b.queue, e.Level); i >= 0 {
+				b.queue = append(b.queue[:i], b.queue[i+1:]...)
+				b.queue = append(b.queue, e)
+				return b.dropped()
+			}
+		case Block:
+			if timeout == nil {
+				maxWait := b.options.MaxWait
+				if wait.maxWait != 0 {
+					maxWait = wait.maxWait
+				}
+				if maxWait < 0 || (wait.ctx != nil && wait.ctx.Err() != nil) {
+					break // Not waiting
+				}
+				if wait.ctx != nil {
+					done = wait.ctx.Done()
+				}
+				globStats.blocked.Add(1)
+				timer := time.NewTimer(maxWait)
+				defer timer.Stop()
+				timeout = timer.C
+			}
+			b.mutex.Unlock()
+			select {
+			case <-b.space:
+				b.mutex.Lock()
+				continue
+			case <-b.done:
+				b.mutex.Lock()
+				continue
+			case <-timeout:
+				b.mutex.Lock()
+			case <-done:
+				b.mutex.Lock()
+			}
+		}
+		return b.dropped()
+	}
+}
+
+// dropped counts a dropped entry and returns an error the first time
+// since the queue became full. Mutex must be held.
+func (b *batcher) dropped() error {
+	globStats.dropped.Add(1)
+	if b.dropping {
+		return nil // Already reported
+	}
+	b.dropping = true
+	return errors.New("llog: sink queue full, dropping entries")
+}
+
+// lowestBelow returns the index of the oldest entry of the lowest level
+// in queue, if lower than level, otherwise -1.
+func lowestBelow(queue []Entry, level Level) int {
+	lowest := -1
+	for i := range queue {
+		if queue[i].Level < level && (lowest < 0 || queue[i].Level < queue[lowest].Level) {
+			lowest = i
+		}
+	}
+	return lowest
+}
+
+// signal signals c, which has a buffer of one, without blocking.
+func signal(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
+
+// close sends the queued entries and stops the batcher.
+func (b *batcher) close() {
+	b.mutex.Lock()
+	if b.closed || !b.started {
+		b.closed = true
+		b.mutex.Unlock()
+		return
+	}
+	b.closed = true
+	signal(b.ready)
+	b.mutex.Unlock()
+	<-b.done
+}
+
+// run collects queued entries into batches until the batcher is closed
+// and the queue is empty.
+func (b *batcher) run() {
+	defer close(b.done)
+	var batch []Entry
+	timer := time.NewTimer(b.options.Interval)
+	timer.Stop()
+	for {
+		b.mutex.Lock()
+		n := min(len(b.queue), b.options.Size-len(batch))
+		batch = append(batch, b.queue[:n]...)
+		b.queue = append(b.queue[:0], b.queue[n:]...)
+		closed := b.closed
+		b.mutex.Unlock()
+		if n > 0 {
+			signal(b.space)
+			if len(batch) == n {
+				timer.Reset(b.options.Interval)
+			}
+		}
+
+		switch {
+		case len(batch) >= b.options.Size:
+			timer.Stop()
+			b.flush(batch)
+			batch = nil
+		case closed && n == 0:
+			b.flush(batch)
+			return
+		case closed:
+		default:
+			select {
+			case <-b.ready:
+			case <-timer.C:
+				b.flush(batch)
+				batch = nil
+			}
+		}
+	}
+}
+
+// flush sends a batch, retrying the entries not sent if it fails. If it
+// still fails the entries not sent are written to the fallback file or
+// dropped.
+func (b *batcher) flush(batch []Entry) {
+	if len(batch) == 0 {
+		return
+	}
+	sent, err := b.send(batch)
+	batch = batch[sent:]
+	delay := batchRetryDelay
+	for i := 0; i < b.options.Retries && err != nil; i++ {
+		time.Sleep(delay)
+		delay *= 2
+		sent, err = b.send(batch)
+		batch = batch[sent:]
+	}
+	if err == nil {
+		return
+	}
+	reportError(fmt.Errorf("llog: unable to send %d entries: %w", len(batch), err))
+	if b.options.Fallback != "" {
+		if err = writeFallback(b.options.Fallback, batch); err == nil {
+			return
+		}
+		reportError(fmt.Errorf("llog: unable to write fallback file: %w", err))
+	}
+	globStats.dropped.Add(uint64(len(batch)))
+}
+
+// writeFallback appends the entries to a fallback file.
+func writeFallback(fileName string, batch []Entry) error {
+	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	var text []byte
+	for _, e := range batch {
+		text = append(text, e.String()+"\n"...)
+	}
+	_, err = file.Write(text)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,127 @@
+// Unit tests for batch
+package llog
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testBatcher returns a batcher which send blocks until release is
+// closed, and a function returning the levels of the entries sent.
+func testBatcher(options BatchOptions, release chan struct{}) (*batcher, func() []Level) {
+	var mutex sync.Mutex
+	var sent []Level
+	b := &batcher{}
+	b.start(options, func(batch []Entry) (int, error) {
+		<-release
+		mutex.Lock()
+		defer mutex.Unlock()
+		for _, e := range batch {
+			sent = append(sent, e.Level)
+		}
+		return len(batch), nil
+	})
+	return b, func() []Level {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return sent
+	}
+}
+
+func TestSaturationDropNewest(t *testing.T) {
+	release := make(chan struct{})
+	b, sent := testBatcher(BatchOptions{Size: 1, QueueSize: 2}, release)
+	b.add(Entry{Level: LvlInfo}) // Taken by the blocked send
+	time.Sleep(50 * time.Millisecond)
+	dropped := Stats().Dropped
+	b.add(Entry{Level: LvlDebug})
+	b.add(Entry{Level: LvlDebug})
+	if err := b.add(Entry{Level: LvlError}); err == nil {
+		t.Fatal("Full queue not reported")
+	}
+	close(release)
+	b.close()
+	if s := sent(); len(s) != 3 || s[2] != LvlDebug || Stats().Dropped != dropped+1 {
+		t.Fatalf("Newest entry not dropped: %v", s)
+	}
+}
+
+func TestSaturationDropLowest(t *testing.T) {
+	release := make(chan struct{})
+	b, sent := testBatcher(BatchOptions{Size: 1, QueueSize: 2, Saturation: DropLowest}, release)
+	b.add(Entry{Level: LvlInfo})
+	time.Sleep(50 * time.Millisecond)
+	b.add(Entry{Level: LvlWarn})
+	b.add(Entry{Level: LvlDebug})
+	b.add(Entry{Level: LvlError}) // Drops debug
+	b.add(Entry{Level: LvlTrace}) // Dropped, since lowest
+	close(release)
+	b.close()
+	if s := sent(); len(s) != 3 || s[1] != LvlWarn || s[2] != LvlError {
+		t.Fatalf("Lowest entries not dropped: %v", s)
+	}
+}
+
+func TestSaturationBlock(t *testing.T) {
+	release := make(chan struct{})
+	b, sent := testBatcher(BatchOptions{Size: 1, QueueSize: 1, Saturation: Block,
+		MaxWait: 50 * time.Millisecond}, release)
+	b.add(Entry{Level: LvlInfo})
+	time.Sleep(50 * time.Millisecond)
+	b.add(Entry{Level: LvlInfo})
+	blocked := Stats().Blocked
+	start := time.Now()
+	if err := b.add(Entry{Level: LvlWarn}); err == nil || time.Since(start) < 50*time.Millisecond {
+		t.Fatal("Write not blocked until max wait")
+	}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+	if err := b.add(Entry{Level: LvlError}); err != nil {
+		t.Fatalf("Write not queued when room. Reason: %s", err)
+	}
+	b.close()
+	if s := sent(); len(s) != 3 || s[2] != LvlError || Stats().Blocked != blocked+2 {
+		t.Fatalf("Blocked entries not sent: %v", s)
+	}
+}
+
+// batcherSink is a sink queuing the entries in a batcher
+type batcherSink struct {
+	b *batcher
+}
+
+func (s batcherSink) Write(e Entry) error { return s.b.add(e) }
+func (s batcherSink) Close() error        { s.b.close(); return nil }
+
+func TestSaturationBlockLogger(t *testing.T) {
+	SetLevel(LvlInfo)
+	release := make(chan struct{})
+	b, sent := testBatcher(BatchOptions{Size: 1, QueueSize: 1, Saturation: Block,
+		MaxWait: 10 * time.Second}, release)
+	sink := batcherSink{b}
+	AddSink(sink)
+	defer RemoveSink(sink)
+	Info("sending")
+	time.Sleep(50 * time.Millisecond)
+	Info("queued")
+
+	start := time.Now()
+	WithMaxWait(0).Info("dropped")
+	WithWorker("w1").WithMaxWait(30 * time.Millisecond).Info("dropped")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	WithContext(ctx).Info("dropped")
+	WithContext(ctx).Info("dropped")
+	if d := time.Since(start); d < 60*time.Millisecond || d > 5*time.Second {
+		t.Fatalf("Writes not limited by the logger or the context: %s", d)
+	}
+	close(release)
+	b.close()
+	if s := sent(); len(s) != 2 {
+		t.Fatalf("Wrong entries sent: %v", s)
+	}
+}
@@ -0,0 +1,309 @@
+package llog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+)
+
+// Format is the format of the log output
+type Format int
+
+const (
+	// FormatText is the default text format:
+	//	2009/01/23 01:23:23 file.go:23: INFO - message
+	FormatText Format = iota
+	// FormatBinary is a compact binary format, see SetFormat
+	FormatBinary
+	// FormatJSON writes each entry as a JSON object on a single line,
+	// see SetFormat
+	FormatJSON
+	// FormatPretty is a format for reading the console during
+	// development, see SetFormat
+	FormatPretty
+)
+
+// SetFormat sets the format of the log file set with SetFile after this
+// call and of the output of the standard logger, when no log file is
+// set. The audit file is always written in the text format.
+//
+// FormatBinary is a compact format for devices with limited storage. The
+// time is stored as a varint difference to the previous entry and file
+// names are only stored the first time they are used in a file. The final
+// path element of the file names is stored, unless the Llongfile flag,
+// but not Lshortfile, is set in the log package. Use
+// BinaryReader or ConvertBinary to read binary log files. FormatBinary is
+// only used for log files, other outputs are written in the text format.
+//
+// FormatJSON writes each entry as a JSON object on a single line, as
+// encoded by Entry.MarshalJSON. The time is only written if the date or
+// time flag is set in the log package, and the file and line only if a
+// file flag is set. The prefix of the standard logger is not written.
+// EntryReader reads log files in the JSON format.
+//
+// FormatPretty is for reading the console during development, for
+// example with go run. The time is written as seconds since SetFormat
+// was called, the levels are colored when written to a terminal, unless
+// the NO_COLOR environment variable is set, and the callers are shortened
+// and aligned:
+//
+//	1.250 INFO  main.go:23      Listening on :8080
+//	3.017 WARN  handler.go:112  [conn-42] Slow request | request_id=4f2a
+//
+// The prefix of the standard logger is not written. FormatPretty is only
+// used for the console, log files are written in the text format.
+func SetFormat(format Format) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globFileOptions.format = format
+	globPretty = nil
+	if format == FormatPretty {
+		globPretty = newPrettyFormat(globClock())
+	}
+	updateOutput()
+}
+
+// Record types in binary log files
+const (
+	// recordStart resets the file names and the time of the previous
+	// entry, at the start of each file and run of the application,
+	// followed by binaryMagic
+	recordStart = 0
+	// recordFile defines a file name: uvarint id, string name
+	recordFile = 1
+	// recordEntry is an entry: varint microseconds since previous
+	// entry, level byte, uvarint file id, uvarint line, uvarint
+	// sequence number, string worker, string message, uvarint number of
+	// fields followed by string key and string value of each field
+	recordEntry = 2
+	// recordText is text, such as a header: string text
+	recordText = 3
+)
+
+// binaryMagic follows recordStart. Strings are encoded as the length
+// (uvarint) followed by the bytes.
+const binaryMagic = "llogb1"
+
+// binaryEncoder encodes entries in the binary format.
+type binaryEncoder struct {
+	started bool              // false if a start record shall be written
+	files   map[string]uint64 // file name to id
+	prev    time.Time         // time of previous entry
+}
+
+// reset makes the encoder write a start record before the next entry,
+// for example when a new file is started or if an entry couldn't be
+// written.
+func (b *binaryEncoder) reset() {
+	b.started = false
+}
+
+// text returns a text record.
+func (b *binaryEncoder) text(text string) []byte {
+	return appendString([]byte{recordText}, text)
+}
+
+// encode returns the entry encoded, preceded by a start record if the
+// encoder has been reset and a file definition if the file of the entry
+// hasn't been used before. The final path element of the file is
+// written, unless the Llongfile flag, but not Lshortfile, is set in
+// flags.
+func (b *binaryEncoder) encode(e *Entry, flags int) []byte {
+	var p []byte
+	if !b.started {
+		p = append(p, recordStart)
+		p = append(p, binaryMagic...)
+		b.files = make(map[string]uint64)
+		b.prev = time.Time{}
+		b.started = true
+	}
+	file := e.File
+	if flags&log.Llongfile == 0 || flags&log.Lshortfile != 0 {
+		file = shortFile(file)
+	}
+	id, ok := b.files[file]
+	if !ok {
+		id = uint64(len(b.files))
+		b.files[file] = id
+		p = append(p, recordFile)
+		p = binary.AppendUvarint(p, id)
+		p = appendString(p, file)
+	}
+	p = append(p, recordEntry)
+	if b.prev.IsZero() {
+		p = binary.AppendVarint(p, e.Time.UnixMicro())
+	} else {
+		p = binary.AppendVarint(p, e.Time.Sub(b.prev).Microseconds())
+	}
+	b.prev = e.Time
+	p = append(p, byte(e.Level))
+	p = binary.AppendUvarint(p, id)
+	p = binary.AppendUvarint(p, uint64(e.Line))
+	p = binary.AppendUvarint(p, e.Seq)
+	p = appendString(p, e.Worker)
+	p = appendString(p, e.Message)
+	p = binary.AppendUvarint(p, uint64(len(e.Fields)))
+	for _, f := range e.Fields {
+		p = appendString(p, f.Key)
+		p = appendString(p, f.Value)
+	}
+	return p
+}
+
+// appendString appends the length and the bytes of s to p.
+func appendString(p []byte, s string) []byte {
+	return append(binary.AppendUvarint(p, uint64(len(s))), s...)
+}
+
+// BinaryReader reads entries from a log file written in FormatBinary.
+type BinaryReader struct {
+	r     *bufio.Reader
+	files map[uint64]string // file id to name
+	prev  time.Time         // time of previous entry
+	err   error             // first error when reading strings
+}
+
+// NewBinaryReader returns a reader reading entries from r.
+func NewBinaryReader(r io.Reader) *BinaryReader {
+	return &BinaryReader{r: bufio.NewReader(r), files: make(map[uint64]string)}
+}
+
+// errInvalidBinary is returned when reading an invalid binary log file
+var errInvalidBinary = errors.New("llog: invalid binary log file")
+
+// Next returns the next entry. io.EOF is returned when there are no more
+// entries. Text, such as headers, is skipped.
+func (r *BinaryReader) Next() (Entry, error) {
+	for {
+		recordType, err := r.r.ReadByte()
+		if err != nil {
+			return Entry{}, err // io.EOF if no more records
+		}
+		switch recordType {
+		case recordStart:
+			magic := make([]byte, len(binaryMagic))
+			if _, err := io.ReadFull(r.r, magic); err != nil || string(magic) != binaryMagic {
+				return Entry{}, errInvalidBinary
+			}
+			r.files = make(map[uint64]string)
+			r.prev = time.Time{}
+		case recordFile:
+			id := r.uvarint()
+			r.files[id] = r.string()
+		case recordText:
+			r.string()
+		case recordEntry:
+			e := r.entry()
+			if r.err != nil {
+				return Entry{}, r.err
+			}
+			return e, nil
+		default:
+			return Entry{}, errInvalidBinary
+		}
+		if r.err != nil {
+			return Entry{}, r.err
+		}
+	}
+}
+
+// entry reads an entry record, after the record type.
+func (r *BinaryReader) entry() Entry {
+	var e Entry
+	t := r.varint()
+	if r.prev.IsZero() {
+		e.Time = time.UnixMicro(t)
+	} else {
+		e.Time = r.prev.Add(time.Duration(t) * time.Microsecond)
+	}
+	r.prev = e.Time
+	level, err := r.r.ReadByte()
+	r.setErr(err)
+	e.Level = Level(level)
+	id := r.uvarint()
+	file, ok := r.files[id]
+	if !ok && r.err == nil {
+		r.err = errInvalidBinary
+	}
+	e.File = file
+	e.Line = int(r.uvarint())
+	e.Seq = r.uvarint()
+	e.Worker = r.string()
+	e.Message = r.string()
+	for n := r.uvarint(); n > 0 && r.err == nil; n-- {
+		e.Fields = append(e.Fields, Field{Key: r.string(), Value: r.string()})
+	}
+	return e
+}
+
+// setErr remembers the first error. io.EOF within a record is an
+// unexpected EOF.
+func (r *BinaryReader) setErr(err error) {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *BinaryReader) uvarint() uint64 {
+	v, err := binary.ReadUvarint(r.r)
+	r.setErr(err)
+	return v
+}
+
+func (r *BinaryReader) varint() int64 {
+	v, err := binary.ReadVarint(r.r)
+	r.setErr(err)
+	return v
+}
+
+func (r *BinaryReader) string() string {
+	n := r.uvarint()
+	if r.err != nil {
+		return ""
+	}
+	if n > 1024*1024*1024 {
+		r.setErr(errInvalidBinary)
+		return ""
+	}
+	b := make([]byte, n)
+	_, err := io.ReadFull(r.r, b)
+	r.setErr(err)
+	return string(b)
+}
+
+// ConvertBinary converts a log file written in FormatBinary to the text
+// format, or to JSON with one entry per line if asJSON is true, see
+// Entry.MarshalJSON. Encrypted log files must be decrypted with Decrypt
+// first.
+func ConvertBinary(dst io.Writer, src io.Reader, asJSON bool) error {
+	reader := NewBinaryReader(src)
+	w := bufio.NewWriter(dst)
+	for {
+		e, err := reader.Next()
+		if err == io.EOF {
+			return w.Flush()
+		}
+		if err != nil {
+			w.Flush()
+			return err
+		}
+		if asJSON {
+			line, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+			w.Write(line)
+			w.WriteByte('\n')
+		} else {
+			fmt.Fprintln(w, e)
+		}
+	}
+}
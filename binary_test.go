@@ -0,0 +1,105 @@
+// Unit tests for the binary format
+package llog
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestBinaryFormat(t *testing.T) {
+	logFileName := "binarylog.txt"
+	os.Remove(logFileName)
+	SetFormat(FormatBinary)
+	defer SetFormat(FormatText)
+	SetHeader(func() string { return "myapp 1.0" })
+	defer SetHeader(nil)
+	SetLevel(LvlInfo)
+	if err := SetFile(logFileName, 1000); err != nil {
+		t.Fatalf("Unable to log to file. Reason: %s", err)
+	}
+	Info("first")
+	WithWorker("conn-42").Warn("second\nwith two lines")
+	Error("third %d", 3)
+	globFile.Close()
+	globFile = nil
+	updateOutput()
+	log.SetOutput(os.Stderr)
+
+	// Restart of application, appending to the file
+	if err := SetFile(logFileName, 1000); err != nil {
+		t.Fatalf("Unable to log to file. Reason: %s", err)
+	}
+	Info("after restart")
+	entries, err := Search(Query{})
+	if err != nil {
+		t.Fatalf("Unable to search binary log. Reason: %s", err)
+	}
+	log.SetOutput(os.Stderr)
+	globFile.Close()
+	globFile = nil
+	updateOutput()
+	defer os.Remove(logFileName)
+
+	if len(entries) != 4 || entries[1].Worker != "conn-42" ||
+		entries[1].Message != "second\nwith two lines" || entries[3].Message != "after restart" {
+		t.Fatalf("Entries not correct: %v", entries)
+	}
+	if entries[0].File != "binary_test.go" || entries[2].Level != LvlError ||
+		entries[2].Time.Before(entries[0].Time) {
+		t.Fatalf("Entries not correct: %v", entries)
+	}
+
+	content, _ := os.ReadFile(logFileName)
+	var text bytes.Buffer
+	if err := ConvertBinary(&text, bytes.NewReader(content), false); err != nil {
+		t.Fatalf("Unable to convert. Reason: %s", err)
+	}
+	if !strings.Contains(text.String(), "binary_test.go:25: ERROR - third 3\n") {
+		t.Fatalf("Wrong text: %s", text.String())
+	}
+	var json bytes.Buffer
+	ConvertBinary(&json, bytes.NewReader(content), true)
+	if strings.Count(json.String(), "\n") != 4 || !strings.Contains(json.String(), `"message":"first"`) {
+		t.Fatalf("Wrong JSON: %s", json.String())
+	}
+	if len(content) > len(text.String()) {
+		t.Fatalf("Binary format (%d bytes) not more compact than text (%d bytes)", len(content), text.Len())
+	}
+	if err := ConvertBinary(&text, strings.NewReader("2019/01/26 INFO - text"), false); err == nil {
+		t.Fatalf("Converting text shall give an error")
+	}
+}
+
+func TestBinaryLongFile(t *testing.T) {
+	logFileName := "binarylong.txt"
+	os.Remove(logFileName)
+	defer os.Remove(logFileName)
+	SetFormat(FormatBinary)
+	defer SetFormat(FormatText)
+	SetLevel(LvlInfo)
+	if err := SetFile(logFileName, 1000); err != nil {
+		t.Fatalf("Unable to log to file. Reason: %s", err)
+	}
+	defer log.SetFlags(log.Flags())
+
+	e := Entry{Level: LvlInfo, File: "/home/user/src/github.com/midstar/app/main.go", Line: 12, Message: "short"}
+	WriteBatch([]Entry{e})
+	log.SetFlags(log.LstdFlags | log.Llongfile)
+	e.Message = "long"
+	WriteBatch([]Entry{e})
+	globFile.Close()
+	globFile = nil
+	updateOutput()
+	log.SetOutput(os.Stderr)
+
+	content, _ := os.ReadFile(logFileName)
+	reader := NewBinaryReader(bytes.NewReader(content))
+	short, _ := reader.Next()
+	long, _ := reader.Next()
+	if short.File != "main.go" || long.File != e.File {
+		t.Fatalf("Wrong files: %q and %q", short.File, long.File)
+	}
+}
@@ -0,0 +1,81 @@
+package llog
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+// SetBuffering buffers writes to log files opened after this call, which
+// drastically reduces the number of write system calls. The buffer is
+// written to the log file when size bytes are buffered, at most
+// flushInterval after an entry has been buffered, on each entry of error
+// level and above and when Flush or Close is called. A flushInterval of 0
+// disables periodic flushing. A size of 0 disables buffering (default).
+//
+// The Entries of the sync policy are not used for buffered files, which
+// are only synced at the Interval or MinLevel of the sync policy, see
+// SetSyncPolicy, or when Close is called.
+//
+// Buffered entries are lost if the application crashes.
+func SetBuffering(size int, flushInterval time.Duration) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globFileOptions.bufferSize = size
+	globFileOptions.flushInterval = flushInterval
+}
+
+// Flush writes any buffered entries to the log files.
+func Flush() error {
+	globMutex.Lock()
+	files := []*logFile{globFile, globAuditFile}
+	globMutex.Unlock()
+	files = append(files, splitLogFiles()...)
+	var firstErr error
+	for _, file := range files {
+		if file == nil {
+			continue
+		}
+		if err := file.Flush(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Close flushes, syncs and closes the log files, including the files
+// added with AddFile, and closes the sinks added with AddSink. Logging
+// continues on stderr.
+func Close() error {
+	firstErr := removeSinks()
+	if err := removeSplitFiles(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	if globFile != nil {
+		log.SetOutput(os.Stderr)
+		if err := closeLogFile(globFile); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		globFile = nil
+	}
+	if globAuditFile != nil {
+		if err := closeLogFile(globAuditFile); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		globAuditFile = nil
+		globAuditLogger = nil
+	}
+	updateOutput()
+	return firstErr
+}
+
+// closeLogFile flushes, syncs and closes a log file.
+func closeLogFile(file *logFile) error {
+	err := file.Sync()
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
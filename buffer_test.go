@@ -0,0 +1,68 @@
+// Unit tests for buffered log files
+package llog
+
+import (
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuffering(t *testing.T) {
+	logFileName := "bufferlog.txt"
+	os.Remove(logFileName)
+	SetBuffering(4096, 20*time.Millisecond)
+	defer SetBuffering(0, 0)
+	SetLevel(LvlInfo)
+	if err := SetFile(logFileName, 100); err != nil {
+		t.Fatalf("Unable to log to file. Reason: %s", err)
+	}
+
+	Info("buffered")
+	content, _ := os.ReadFile(logFileName)
+	if len(content) > 0 {
+		t.Fatalf("Entry shall be buffered:\n%s", content)
+	}
+	time.Sleep(100 * time.Millisecond)
+	content, _ = os.ReadFile(logFileName)
+	if !strings.Contains(string(content), "INFO - buffered") {
+		t.Fatalf("Entry shall be flushed after interval:\n%s", content)
+	}
+
+	Error("an error")
+	content, _ = os.ReadFile(logFileName)
+	if !strings.Contains(string(content), "ERROR - an error") {
+		t.Fatalf("Error entry shall be flushed directly:\n%s", content)
+	}
+
+	Info("flushed")
+	Flush()
+	content, _ = os.ReadFile(logFileName)
+	if !strings.Contains(string(content), "INFO - flushed") {
+		t.Fatalf("Entry shall be flushed by Flush:\n%s", content)
+	}
+
+	for i := 0; i < 30; i++ {
+		Info("many %d", i) // More than the entries of the sync policy
+	}
+	content, _ = os.ReadFile(logFileName)
+	if strings.Contains(string(content), "INFO - many") {
+		t.Fatalf("Entries shall be buffered until the buffer is full:\n%s", content)
+	}
+
+	Info("closed")
+	if err := Close(); err != nil {
+		t.Fatalf("Unable to close. Reason: %s", err)
+	}
+	if log.Writer() != os.Stderr {
+		t.Fatalf("Logging shall continue on stderr after Close")
+	}
+	content, _ = os.ReadFile(logFileName)
+	if !strings.Contains(string(content), "INFO - closed") {
+		t.Fatalf("Entry shall be flushed by Close:\n%s", content)
+	}
+
+	// Cleanup
+	os.Remove(logFileName)
+}
@@ -0,0 +1,136 @@
+package llog
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// bundleManifest is the content of manifest.json in a bundle.
+type bundleManifest struct {
+	Created time.Time    `json:"created"`
+	Host    string       `json:"host"`
+	Args    []string     `json:"args"`
+	Level   string       `json:"level"`
+	Files   []bundleFile `json:"files"`
+	Stats   Statistics   `json:"stats"`
+}
+
+// bundleFile describes a log file in the manifest.
+type bundleFile struct {
+	Name       string      `json:"name"`
+	MaxSizeKB  int         `json:"maxSizeKB"`
+	Binary     bool        `json:"binary"`
+	Encrypted  bool        `json:"encrypted"`
+	Chained    bool        `json:"chained"`
+	WrapMode   string      `json:"wrapMode"`
+	FailMode   string      `json:"failMode"`
+	Sync       SyncPolicy  `json:"sync"`
+	BufferSize int         `json:"bufferSize"`
+	Wraps      []time.Time `json:"wraps"`
+}
+
+// ExportBundle writes a zip archive with the log file set with SetFile,
+// the audit file set with SetAuditFile, the files added with AddFile,
+// their backups and a manifest, manifest.json, with the configuration,
+// statistics and the times of the latest wraps. The archive is intended
+// to be attached to support tickets. Encrypted log files are exported
+// encrypted.
+func ExportBundle(w io.Writer) error {
+	globMutex.Lock()
+	level := levelSet()
+	files := []*logFile{globFile, globAuditFile}
+	globMutex.Unlock()
+	files = append(files, splitLogFiles()...)
+
+	host, _ := os.Hostname()
+	manifest := bundleManifest{
+		Created: now(),
+		Host:    host,
+		Args:    os.Args,
+		Level:   level.String(),
+		Stats:   Stats(),
+	}
+	archive := zip.NewWriter(w)
+	for _, f := range files {
+		if f == nil {
+			continue
+		}
+		f.Flush()
+		manifest.Files = append(manifest.Files, f.describe())
+		for _, fileName := range []string{f.name + ".1", f.name} {
+			if err := addToBundle(archive, fileName); err != nil {
+				return err
+			}
+		}
+	}
+
+	m, err := archive.Create("manifest.json")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(m)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// describe returns the description of the log file in the manifest.
+func (f *logFile) describe() bundleFile {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	wrapMode, failMode := "rename", "stderr"
+	if f.wrapMode == WrapCopyTruncate {
+		wrapMode = "copytruncate"
+	}
+	switch f.failMode {
+	case FailDrop:
+		failMode = "drop"
+	case FailBlock:
+		failMode = "block"
+	}
+	return bundleFile{
+		Name:       filepath.Base(f.name),
+		MaxSizeKB:  f.maxSizeKB,
+		Binary:     f.binary != nil,
+		Encrypted:  f.aead != nil,
+		Chained:    f.chain != nil,
+		WrapMode:   wrapMode,
+		FailMode:   failMode,
+		Sync:       f.sync,
+		BufferSize: f.bufferSize,
+		Wraps:      append([]time.Time(nil), f.wraps...),
+	}
+}
+
+// addToBundle adds a file to the archive, if the file exists.
+func addToBundle(archive *zip.Writer, fileName string) error {
+	file, err := os.Open(fileName)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Method = zip.Deflate
+	w, err := archive.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, file)
+	return err
+}
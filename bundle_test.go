@@ -0,0 +1,65 @@
+// Unit tests for bundle
+package llog
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExportBundle(t *testing.T) {
+	logFileName := "bundlelog.txt"
+	backupFileName := logFileName + ".1"
+	os.Remove(logFileName)
+	os.Remove(backupFileName)
+	SetLevel(LvlInfo)
+	if err := SetFile(logFileName, 1); err != nil {
+		t.Fatalf("Unable to log to file. Reason: %s", err)
+	}
+	for i := 0; i < 30; i++ {
+		Info("entry %d", i)
+	}
+
+	var buffer bytes.Buffer
+	if err := ExportBundle(&buffer); err != nil {
+		t.Fatalf("Unable to export bundle. Reason: %s", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatalf("Bundle is not a zip archive. Reason: %s", err)
+	}
+	content := make(map[string]string)
+	for _, f := range archive.File {
+		r, _ := f.Open()
+		b, _ := io.ReadAll(r)
+		r.Close()
+		content[f.Name] = string(b)
+	}
+	if !strings.Contains(content[backupFileName], "entry 0") {
+		t.Fatalf("Backup file missing in bundle: %v", content)
+	}
+	if !strings.Contains(content[logFileName], "entry 29") {
+		t.Fatalf("Log file missing in bundle: %v", content)
+	}
+	var manifest bundleManifest
+	if err := json.Unmarshal([]byte(content["manifest.json"]), &manifest); err != nil {
+		t.Fatalf("Invalid manifest. Reason: %s", err)
+	}
+	if manifest.Level != "INFO" || len(manifest.Files) != 1 ||
+		manifest.Files[0].Name != logFileName || len(manifest.Files[0].Wraps) != 1 {
+		t.Fatalf("Manifest not correct: %s", content["manifest.json"])
+	}
+
+	// Cleanup
+	log.SetOutput(os.Stderr)
+	globFile.Close()
+	globFile = nil
+	updateOutput()
+	os.Remove(logFileName)
+	os.Remove(backupFileName)
+}
@@ -0,0 +1,55 @@
+package llog
+
+import "log"
+
+// CallerOption changes the caller, i.e. the file and line, written with
+// the entries of a logger, see WithCaller
+type CallerOption func(l *Logger)
+
+// NoCaller writes entries without caller, for example from generated
+// code where the location is meaningless.
+func NoCaller() CallerOption {
+	return func(l *Logger) {
+		l.noCaller = true
+	}
+}
+
+// CallerSkip writes the caller skip frames further up the stack, for
+// example the caller of a helper function writing entries, instead of
+// the helper itself. Skips add up if given several times.
+func CallerSkip(skip int) CallerOption {
+	return func(l *Logger) {
+		l.callerSkip += skip
+	}
+}
+
+// WithCaller returns a logger writing entries with the caller changed by
+// opts, without changing the flags of the standard logger. Use it on a
+// derived logger or per call:
+//
+//	func check(err error) {
+//		if err != nil {
+//			llog.WithCaller(llog.CallerSkip(1)).Error("Failed: %v", err) // Caller of check
+//		}
+//	}
+func WithCaller(opts ...CallerOption) *Logger {
+	return globLogger.WithCaller(opts...)
+}
+
+// WithCaller returns a copy of the logger writing entries with the
+// caller changed by opts.
+func (l *Logger) WithCaller(opts ...CallerOption) *Logger {
+	c := *l
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return &c
+}
+
+// callerFlags returns the flags to use for the entries of the logger.
+func (l *Logger) callerFlags(flags int) int {
+	if l.noCaller {
+		return flags &^ (log.Lshortfile | log.Llongfile)
+	}
+	return flags
+}
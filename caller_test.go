@@ -0,0 +1,52 @@
+// Unit tests for caller
+package llog
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+)
+
+// logHelper writes an entry on behalf of its caller.
+func logHelper(msg string) {
+	WithCaller(CallerSkip(1)).Info("%s", msg)
+}
+
+func TestWithCaller(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	flags := log.Flags()
+	log.SetFlags(log.Lshortfile)
+	defer log.SetFlags(flags)
+	SetLevel(LvlInfo)
+	sink := &testSink{}
+	AddSink(sink)
+	defer RemoveSink(sink)
+
+	logHelper("skipped")
+	_, line, _ := caller(0)
+	WithCaller(NoCaller()).Info("no caller")
+	WithWorker("w").WithCaller(CallerSkip(1), CallerSkip(-1)).Info("skips add up")
+
+	want := fmt.Sprintf("caller_test.go:%d: INFO - skipped\n", line-1) +
+		"INFO - no caller\n" +
+		fmt.Sprintf("caller_test.go:%d: [w] INFO - skips add up\n", line+2)
+	if got := buffer.String(); got != want {
+		t.Fatalf("Got:\n%s\nWant:\n%s", got, want)
+	}
+	if len(sink.entries) != 3 || sink.entries[1].File != "" || sink.entries[1].Line != 0 {
+		t.Fatalf("Caller written to sink: %v", sink.entries)
+	}
+
+	// No file in the JSON format
+	SetFormat(FormatJSON)
+	defer SetFormat(FormatText)
+	buffer.Reset()
+	WithCaller(NoCaller()).Info("json")
+	if e, ok := parseJSONLine(buffer.String()); !ok || e.File != "" || e.Message != "json" {
+		t.Fatalf("Caller written in JSON: %s", buffer.String())
+	}
+}
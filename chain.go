@@ -0,0 +1,164 @@
+package llog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+)
+
+// chainField is appended, together with the hex encoded HMAC, to each
+// entry in a hash chained file.
+const chainField = " chain="
+
+// chainStart is the message of the first entry in a hash chained file.
+const chainStart = "llog audit chain start prev="
+
+// globAuditKey is the key used to hash chain audit files or nil if
+// audit files shall not be hash chained
+var globAuditKey []byte
+
+// SetAuditChain enables tamper-evident audit files. Each entry written
+// to an audit file opened after this call is sealed with a HMAC-SHA256
+// over the entry and the HMAC of the previous entry, using key. Use
+// VerifyAudit to detect modification or removal of entries.
+//
+// A nil key disables hash chaining for audit files opened after this call.
+func SetAuditChain(key []byte) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globAuditKey = key
+}
+
+// hashChain seals entries with a HMAC of the entry and the previous HMAC.
+type hashChain struct {
+	key  []byte
+	prev []byte // HMAC of previous entry or nil if no entry exist
+}
+
+// sum calculates the HMAC of line in the chain.
+func (c *hashChain) sum(prev []byte, line []byte) []byte {
+	if prev == nil {
+		prev = make([]byte, sha256.Size)
+	}
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(prev)
+	mac.Write(line)
+	return mac.Sum(nil)
+}
+
+// seal returns p, without trailing newline, with the chain field
+// appended and advances the chain.
+func (c *hashChain) seal(p []byte) []byte {
+	line := bytes.TrimSuffix(p, []byte("\n"))
+	c.prev = c.sum(c.prev, line)
+	sealed := make([]byte, 0, len(line)+len(chainField)+2*sha256.Size+1)
+	sealed = append(sealed, line...)
+	sealed = append(sealed, chainField...)
+	sealed = append(sealed, hex.EncodeToString(c.prev)...)
+	return append(sealed, '\n')
+}
+
+// start returns a sealed chain start entry, which refers to the HMAC of
+// the previous entry (possibly in a previous file).
+func (c *hashChain) start() []byte {
+	prev := c.prev
+	if prev == nil {
+		prev = make([]byte, sha256.Size)
+	}
+	return c.seal([]byte(chainStart + hex.EncodeToString(prev)))
+}
+
+// splitSealed splits a sealed entry into the entry and its HMAC. ok is
+// false if the entry is not sealed.
+func splitSealed(entry []byte) (line []byte, sum []byte, ok bool) {
+	i := bytes.LastIndex(entry, []byte(chainField))
+	if i < 0 {
+		return entry, nil, false
+	}
+	sum, err := hex.DecodeString(string(entry[i+len(chainField):]))
+	if err != nil || len(sum) != sha256.Size {
+		return entry, nil, false
+	}
+	return entry[:i], sum, true
+}
+
+// resumeHashChain creates a hash chain that continues the chain in
+// fileName, if any. If aead is not nil the file is encrypted.
+func resumeHashChain(fileName string, key []byte, aead cipher.AEAD) (*hashChain, error) {
+	chain := &hashChain{key: key}
+	file, err := openLogReader(fileName, aead)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		if _, sum, ok := splitSealed(scanner.Bytes()); ok {
+			chain.prev = sum
+		}
+	}
+	return chain, scanner.Err()
+}
+
+// VerifyAudit verifies that the hash chain in an audit file written
+// with SetAuditChain is intact. An error is returned if any entry has
+// been modified, inserted or removed, or if entries at the beginning of
+// the file have been removed.
+//
+// Removal of entries at the end of the file can not be detected from
+// the file itself. Neither can it be verified that the first entry
+// continues the chain in the backup file. Encrypted audit files must be
+// decrypted with Decrypt before they are verified.
+func VerifyAudit(fileName string, key []byte) error {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	chain := &hashChain{key: key}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1024*1024)
+	var entry []byte // Entries might span several lines
+	lineNbr, entryNbr := 0, 0
+	for scanner.Scan() {
+		lineNbr++
+		entry = append(entry, scanner.Bytes()...)
+		line, sum, ok := splitSealed(entry)
+		if !ok {
+			entry = append(entry, '\n')
+			continue
+		}
+		entryNbr++
+		if entryNbr == 1 {
+			if !bytes.HasPrefix(line, []byte(chainStart)) {
+				return fmt.Errorf("llog: %s line %d: chain start missing, beginning of file removed", fileName, lineNbr)
+			}
+			chain.prev, err = hex.DecodeString(string(line[len(chainStart):]))
+			if err != nil || len(chain.prev) != sha256.Size {
+				return fmt.Errorf("llog: %s line %d: invalid chain start", fileName, lineNbr)
+			}
+		}
+		chain.prev = chain.sum(chain.prev, line)
+		if !hmac.Equal(chain.prev, sum) {
+			return fmt.Errorf("llog: %s line %d: entry modified or previous entry removed", fileName, lineNbr)
+		}
+		entry = entry[:0]
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(entry) > 0 {
+		return fmt.Errorf("llog: %s line %d: entry not sealed", fileName, lineNbr)
+	}
+	if entryNbr == 0 {
+		return fmt.Errorf("llog: %s: no chain found", fileName)
+	}
+	return nil
+}
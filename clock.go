@@ -0,0 +1,25 @@
+package llog
+
+import "time"
+
+// globClock returns the current time, see SetClock
+var globClock = time.Now
+
+// SetClock sets a function returning the current time, used for the time
+// of entries, wraps and other times written by llog, so that tests and
+// simulations get deterministic timestamps. A nil clock restores the
+// default, time.Now.
+func SetClock(clock func() time.Time) {
+	if clock == nil {
+		clock = time.Now
+	}
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globClock = clock
+	updateOutput()
+}
+
+// now returns the current time of the clock set with SetClock.
+func now() time.Time {
+	return globOutput.Load().clock()
+}
@@ -0,0 +1,37 @@
+// Unit tests for clock
+package llog
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetClock(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+
+	clock := time.Date(2001, 2, 3, 4, 5, 6, 0, time.Local)
+	SetClock(func() time.Time { return clock })
+	defer SetClock(nil)
+	Info("first")
+	clock = clock.Add(time.Hour)
+	WriteBatch([]Entry{{Level: LvlWarn, Message: "batch"}})
+
+	output := buffer.String()
+	if !strings.HasPrefix(output, "2001/02/03 04:05:06 clock_test.go:22: INFO - first\n") ||
+		!strings.Contains(output, "2001/02/03 05:05:06 ") {
+		t.Fatalf("Clock not used: %s", output)
+	}
+	SetClock(nil)
+	buffer.Reset()
+	Info("now")
+	if strings.HasPrefix(buffer.String(), "2001/") {
+		t.Fatalf("Default clock not restored: %s", buffer.String())
+	}
+}
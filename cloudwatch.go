@@ -0,0 +1,133 @@
+package llog
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CloudWatchEvent is a log event sent to CloudWatch Logs.
+type CloudWatchEvent struct {
+	// Timestamp in milliseconds since 1970-01-01 UTC
+	Timestamp int64
+	// Message is the entry on the default log format
+	Message string
+}
+
+// CloudWatchClient puts log events to a log stream in CloudWatch Logs.
+// llog doesn't depend on the AWS SDK, instead the application implements
+// the client using PutLogEvents in the SDK. If the sequence token is
+// invalid the client shall return a *CloudWatchSequenceTokenError.
+type CloudWatchClient interface {
+	PutLogEvents(ctx context.Context, group, stream string, events []CloudWatchEvent,
+		sequenceToken string) (nextSequenceToken string, err error)
+}
+
+// CloudWatchSequenceTokenError is returned by a CloudWatchClient when
+// the sequence token is invalid, i.e. InvalidSequenceTokenException or
+// DataAlreadyAcceptedException in the AWS SDK.
+type CloudWatchSequenceTokenError struct {
+	// ExpectedSequenceToken is the sequence token to use
+	ExpectedSequenceToken string
+}
+
+func (e *CloudWatchSequenceTokenError) Error() string {
+	return "invalid sequence token, expected " + e.ExpectedSequenceToken
+}
+
+// CloudWatch is a sink, see AddSink, sending entries to a log stream in
+// AWS CloudWatch Logs. The entries are sent in batches, oldest first,
+// within the limits of PutLogEvents.
+type CloudWatch struct {
+	// Client puts the log events
+	Client CloudWatchClient
+	// Group and Stream are the log group and log stream
+	Group  string
+	Stream string
+	// Timeout of each call to the client. Default is 10 seconds.
+	Timeout time.Duration
+	// Batch configures the batching of entries. Use Batch.Fallback to
+	// keep the entries in a local file when CloudWatch is unreachable.
+	Batch BatchOptions
+
+	once          sync.Once
+	batcher       batcher
+	sequenceToken string // only used by the batcher
+}
+
+// Limits of PutLogEvents
+const (
+	cloudWatchMaxEvents     = 10000
+	cloudWatchMaxBatchBytes = 1048576
+	cloudWatchEventOverhead = 26
+)
+
+// Write queues the entry to be sent.
+func (c *CloudWatch) Write(e Entry) error {
+	c.once.Do(c.start)
+	return c.batcher.add(e)
+}
+
+// Close sends the queued entries.
+func (c *CloudWatch) Close() error {
+	c.once.Do(func() {})
+	c.batcher.close()
+	return nil
+}
+
+// start fills in defaults and starts the batcher.
+func (c *CloudWatch) start() {
+	if c.Timeout == 0 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.Batch.Size == 0 || c.Batch.Size > cloudWatchMaxEvents {
+		c.Batch.Size = cloudWatchMaxEvents
+	}
+	c.batcher.start(c.Batch, c.send)
+}
+
+// send sends a batch, split into several calls if it exceeds the size
+// limit of PutLogEvents.
+func (c *CloudWatch) send(batch []Entry) (int, error) {
+	sort.SliceStable(batch, func(i, j int) bool { return batch[i].Time.Before(batch[j].Time) })
+	sent := 0
+	for sent < len(batch) {
+		var events []CloudWatchEvent
+		size := 0
+		for _, e := range batch[sent:] {
+			message := e.String()
+			if len(events) > 0 && size+len(message)+cloudWatchEventOverhead > cloudWatchMaxBatchBytes {
+				break
+			}
+			size += len(message) + cloudWatchEventOverhead
+			events = append(events, CloudWatchEvent{Timestamp: e.Time.UnixMilli(), Message: message})
+		}
+		if err := c.put(events); err != nil {
+			return sent, err
+		}
+		sent += len(events)
+	}
+	return sent, nil
+}
+
+// put puts the events, retrying once with the expected sequence token
+// if the sequence token is invalid.
+func (c *CloudWatch) put(events []CloudWatchEvent) error {
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+		token, err := c.Client.PutLogEvents(ctx, c.Group, c.Stream, events, c.sequenceToken)
+		cancel()
+		var tokenErr *CloudWatchSequenceTokenError
+		if errors.As(err, &tokenErr) && attempt == 0 {
+			c.sequenceToken = tokenErr.ExpectedSequenceToken
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		c.sequenceToken = token
+		return nil
+	}
+}
@@ -0,0 +1,76 @@
+// Unit tests for the CloudWatch sink
+package llog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testCloudWatchClient is a CloudWatch client keeping the events put
+type testCloudWatchClient struct {
+	mutex    sync.Mutex
+	token    string // expected sequence token
+	calls    int
+	failCall int // call that fails, 0 if none
+	events   []CloudWatchEvent
+}
+
+func (c *testCloudWatchClient) PutLogEvents(ctx context.Context, group, stream string,
+	events []CloudWatchEvent, sequenceToken string) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls++
+	if group != "mygroup" || stream != "mystream" {
+		return "", errors.New("no such stream")
+	}
+	if sequenceToken != c.token {
+		return "", &CloudWatchSequenceTokenError{ExpectedSequenceToken: c.token}
+	}
+	if c.calls == c.failCall {
+		return "", errors.New("unreachable")
+	}
+	c.events = append(c.events, events...)
+	c.token += "x"
+	return c.token, nil
+}
+
+func TestCloudWatch(t *testing.T) {
+	log.SetOutput(new(bytes.Buffer))
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	batchRetryDelay = time.Millisecond
+	defer func() { batchRetryDelay = 500 * time.Millisecond }()
+
+	// Sequence token is expected to be "tok", which the sink doesn't know.
+	// The third entry is put in a second call, which fails the first time.
+	client := &testCloudWatchClient{token: "tok", failCall: 3}
+	sink := &CloudWatch{Client: client, Group: "mygroup", Stream: "mystream"}
+	AddSink(sink)
+	large := strings.Repeat("a", 400*1024)
+	Info("first %s", large)
+	Info("second %s", large)
+	Info("third %s", large)
+	RemoveSink(sink)
+
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	if len(client.events) != 3 || client.calls != 4 {
+		t.Fatalf("Expected 3 events in 4 calls, got %d events in %d calls",
+			len(client.events), client.calls)
+	}
+	for i, prefix := range []string{"first", "second", "third"} {
+		if !strings.Contains(client.events[i].Message, "INFO - "+prefix) {
+			t.Fatalf("Event %d not correct: %.60s", i, client.events[i].Message)
+		}
+	}
+	if time.Since(time.UnixMilli(client.events[0].Timestamp)) > time.Minute {
+		t.Fatalf("Wrong timestamp: %d", client.events[0].Timestamp)
+	}
+}
@@ -0,0 +1,228 @@
+package llog
+
+import (
+	"context"
+	"crypto/cipher"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// Config is the global configuration of llog, see Snapshot and Restore.
+// The fields tell what is set with the corresponding functions, for
+// example Level with SetLevel and File with SetFile, and can be changed
+// before the configuration is restored.
+//
+// Sinks, hooks, the webhook, the crash dump and files added with AddFile
+// are not part of the configuration, since they are removed with their
+// own functions.
+type Config struct {
+	Level             Level
+	OutputLevel       Level
+	Format            Format
+	File              string // log file or "" for the output of the standard logger
+	MaxSizeKB         int
+	AuditFile         string // audit file or "" for the same output as the log
+	AuditMaxSizeKB    int
+	WrapMode          WrapMode
+	WrapOnOpen        bool
+	WrapInterval      time.Duration
+	FailMode          FailMode
+	Sync              SyncPolicy
+	BufferSize        int
+	FlushInterval     time.Duration
+	FileLocking       bool
+	JSONMetadata      bool
+	DualStream        bool
+	SequenceNumbers   bool
+	MessageIDs        bool
+	Sanitize          bool
+	MaxMessageLength  int
+	StackOnFirstError bool
+	ProgressInterval  time.Duration
+	Prefix            string // prefix of the standard logger
+	Flags             int    // flags of the standard logger
+
+	writer         io.Writer // output of the standard logger
+	file           *logFile
+	auditFile      *logFile
+	aead           cipher.AEAD
+	auditKey       []byte
+	header         func() string
+	clock          func() time.Time
+	traceExtractor func(ctx context.Context) (traceID, spanID string)
+	formatter      Formatter
+	eventLevels    map[EventCode]Level
+	pretty         *prettyFormat
+	errorHandler   func(error)
+}
+
+// Snapshot returns the current configuration, so that it can be changed
+// temporarily, for example by a test, and put back with Restore:
+//
+//	cfg := llog.Snapshot()
+//	defer llog.Restore(cfg)
+//	llog.SetLevel(llog.LvlTrace)
+func Snapshot() Config {
+	globErrorMutex.Lock()
+	errorHandler := globErrorHandler
+	globErrorMutex.Unlock()
+
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	options := globFileOptions
+	cfg := Config{
+		Level:             levelSet(),
+		OutputLevel:       globOutputLevel,
+		Format:            options.format,
+		WrapMode:          options.wrapMode,
+		WrapOnOpen:        options.wrapOnOpen,
+		WrapInterval:      options.wrapInterval,
+		FailMode:          options.failMode,
+		Sync:              options.sync,
+		BufferSize:        options.bufferSize,
+		FlushInterval:     options.flushInterval,
+		FileLocking:       options.locking,
+		JSONMetadata:      options.metadata,
+		DualStream:        globDualStream,
+		SequenceNumbers:   globSequenceEnabled,
+		MessageIDs:        globMessageIDs,
+		Sanitize:          globSanitize,
+		MaxMessageLength:  globMaxMessageLength,
+		StackOnFirstError: globStackOnFirstError,
+		ProgressInterval:  globProgressInterval,
+		Prefix:            log.Prefix(),
+		Flags:             log.Flags(),
+		writer:            log.Writer(),
+		file:              globFile,
+		auditFile:         globAuditFile,
+		aead:              options.aead,
+		auditKey:          globAuditKey,
+		header:            globHeader,
+		clock:             globClock,
+		traceExtractor:    globTraceExtractor,
+		formatter:         globFormatter,
+		eventLevels:       globEventLevels,
+		pretty:            globPretty,
+		errorHandler:      errorHandler,
+	}
+	if globFile != nil {
+		cfg.File, cfg.MaxSizeKB = globFile.name, globFile.maxSizeKB
+		cfg.writer = os.Stderr // If the file is changed to none
+	}
+	if globAuditFile != nil {
+		cfg.AuditFile, cfg.AuditMaxSizeKB = globAuditFile.name, globAuditFile.maxSizeKB
+	}
+	return cfg
+}
+
+// Restore restores a configuration returned by Snapshot. The log file
+// and the audit file are kept if they are the same as in the snapshot,
+// otherwise they are closed and the files in the snapshot are opened
+// again, without wrapping them as SetWrapOnOpen would. If a file can't be
+// opened the error is returned and the rest of the configuration is
+// still restored.
+func Restore(cfg Config) error {
+	SetLevel(cfg.Level)
+	SetErrorHandler(cfg.errorHandler)
+	log.SetPrefix(cfg.Prefix)
+	log.SetFlags(cfg.Flags)
+
+	globMutex.Lock()
+	globFileOptions = fileOptions{
+		aead:          cfg.aead,
+		locking:       cfg.FileLocking,
+		wrapMode:      cfg.WrapMode,
+		wrapOnOpen:    cfg.WrapOnOpen,
+		wrapInterval:  cfg.WrapInterval,
+		failMode:      cfg.FailMode,
+		sync:          cfg.Sync,
+		bufferSize:    cfg.BufferSize,
+		flushInterval: cfg.FlushInterval,
+		format:        cfg.Format,
+		metadata:      cfg.JSONMetadata,
+	}
+	globOutputLevel = cfg.OutputLevel
+	globDualStream = cfg.DualStream
+	globSequenceEnabled = cfg.SequenceNumbers
+	globMessageIDs = cfg.MessageIDs
+	globSanitize = cfg.Sanitize
+	globMaxMessageLength = cfg.MaxMessageLength
+	globStackOnFirstError = cfg.StackOnFirstError
+	globProgressInterval = cfg.ProgressInterval
+	globAuditKey = cfg.auditKey
+	globHeader = cfg.header
+	globClock = cfg.clock
+	if globClock == nil {
+		globClock = time.Now
+	}
+	globTraceExtractor = cfg.traceExtractor
+	globFormatter = cfg.formatter
+	globEventLevels = cfg.eventLevels
+	globPretty = nil
+	if cfg.Format == FormatPretty {
+		globPretty = cfg.pretty
+		if globPretty == nil {
+			globPretty = newPrettyFormat(globClock())
+		}
+	}
+
+	// Files that are not the same as in the snapshot are closed and, if
+	// set in the snapshot, opened again below
+	var firstErr error
+	reopenFile := cfg.File != "" && !sameFile(globFile, cfg.file, cfg.File, cfg.MaxSizeKB)
+	if globFile != nil && (cfg.File == "" || reopenFile) {
+		if err := closeLogFile(globFile); err != nil {
+			firstErr = err
+		}
+		globFile = nil
+	}
+	if cfg.writer == nil {
+		cfg.writer = os.Stderr
+	}
+	if globFile == nil {
+		log.SetOutput(cfg.writer)
+	}
+	reopenAudit := cfg.AuditFile != "" &&
+		!sameFile(globAuditFile, cfg.auditFile, cfg.AuditFile, cfg.AuditMaxSizeKB)
+	if globAuditFile != nil && (cfg.AuditFile == "" || reopenAudit) {
+		if err := closeLogFile(globAuditFile); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		globAuditFile = nil
+		globAuditLogger = nil
+	}
+	// The files are not wrapped when opened again, since they were in use
+	options := globFileOptions
+	options.wrapOnOpen = false
+	updateOutput()
+	globMutex.Unlock()
+
+	if reopenFile {
+		if file, err := openLogFileOptions(cfg.File, cfg.MaxSizeKB, options); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+		} else {
+			setLogFile(file)
+		}
+	}
+	if reopenAudit {
+		file, err := openLogFileOptions(cfg.AuditFile, cfg.AuditMaxSizeKB, options)
+		if err == nil {
+			err = setAuditFile(file)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// sameFile returns true if the current file is the file of a snapshot,
+// with the name and size given in the snapshot.
+func sameFile(current, snapshot *logFile, name string, maxSizeKB int) bool {
+	return current != nil && current == snapshot && current.name == name &&
+		current.maxSizeKB == maxSizeKB
+}
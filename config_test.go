@@ -0,0 +1,92 @@
+// Unit tests for config
+package llog
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	cfg := Snapshot()
+	if cfg.Level != LvlInfo || cfg.File != "" || cfg.Format != FormatText || cfg.Flags != log.Flags() {
+		t.Fatalf("Wrong snapshot: %+v", cfg)
+	}
+
+	// Change the configuration temporarily
+	logFileName := filepath.Join(t.TempDir(), "config.log")
+	SetLevel(LvlTrace)
+	SetFormat(FormatJSON)
+	SetMaxMessageLength(5)
+	SetEventLevel("E1", LvlError)
+	SetClock(func() time.Time { return time.Date(2001, 2, 3, 4, 5, 6, 0, time.Local) })
+	if err := SetFile(logFileName, 10); err != nil {
+		t.Fatalf("Unable to set file. Reason: %s", err)
+	}
+	changed := Snapshot()
+	if changed.Level != LvlTrace || changed.Format != FormatJSON || changed.MaxMessageLength != 5 ||
+		changed.File != logFileName || changed.MaxSizeKB != 10 {
+		t.Fatalf("Wrong snapshot: %+v", changed)
+	}
+
+	if err := Restore(cfg); err != nil {
+		t.Fatalf("Unable to restore. Reason: %s", err)
+	}
+	Debug("hidden")
+	Info("restored message")
+	if !strings.HasSuffix(buffer.String(), "INFO - restored message\n") ||
+		strings.HasPrefix(buffer.String(), "2001/") {
+		t.Fatalf("Configuration not restored: %q", buffer.String())
+	}
+	if restored := Snapshot(); restored.File != "" || restored.Level != LvlInfo ||
+		len(restored.eventLevels) != len(cfg.eventLevels) || restored.MaxMessageLength != 0 {
+		t.Fatalf("Wrong configuration after restore: %+v", restored)
+	}
+
+	// Restoring a configuration with a file opens the file again
+	buffer.Reset()
+	if err := Restore(changed); err != nil {
+		t.Fatalf("Unable to restore. Reason: %s", err)
+	}
+	WithEvent("E1").Info("to file")
+	if err := Restore(cfg); err != nil {
+		t.Fatalf("Unable to restore. Reason: %s", err)
+	}
+	content, _ := os.ReadFile(logFileName)
+	if buffer.Len() != 0 || !strings.Contains(string(content), `"level":"ERROR"`) ||
+		!strings.Contains(string(content), `"message":"to fi…[truncated 2 bytes]"`) {
+		t.Fatalf("File not restored: %q", content)
+	}
+}
+
+func TestRestoreWithoutWrap(t *testing.T) {
+	logFileName := filepath.Join(t.TempDir(), "restore.log")
+	os.WriteFile(logFileName+".1", []byte("backup\n"), 0666)
+	os.WriteFile(logFileName, []byte("current\n"), 0666)
+	if err := SetFile(logFileName, 1024); err != nil {
+		t.Fatalf("Unable to set file. Reason: %s", err)
+	}
+	defer Close()
+	SetWrapOnOpen(true)
+	cfg := Snapshot()
+	SetWrapOnOpen(false)
+	Close()
+
+	if err := Restore(cfg); err != nil {
+		t.Fatalf("Unable to restore. Reason: %s", err)
+	}
+	SetWrapOnOpen(false)
+	backup, _ := os.ReadFile(logFileName + ".1")
+	current, _ := os.ReadFile(logFileName)
+	if string(backup) != "backup\n" || string(current) != "current\n" {
+		t.Fatalf("Restored file wrapped: %q %q", backup, current)
+	}
+}
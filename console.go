@@ -0,0 +1,58 @@
+package llog
+
+import (
+	"io"
+	"log"
+	"os"
+)
+
+// globDualStream is true if entries are written to stdout and stderr
+// depending on level, see SetDualStream
+var globDualStream bool
+
+// globStdout and globStderr are the streams used in dual stream mode
+var globStdout, globStderr io.Writer = os.Stdout, os.Stderr
+
+// SetDualStream writes entries on warn level and above to stderr and
+// entries below warn level to stdout, following the twelve-factor
+// conventions, so that container orchestrators and shells can separate
+// the error stream without parsing the level. Dual stream mode is only
+// used when no log file is set with SetFile, and then replaces the
+// output of the standard logger. Audit entries are written to stderr,
+// unless an audit file is set.
+func SetDualStream(enable bool) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globDualStream = enable
+	updateOutput()
+}
+
+// consoleWriter returns the stream to write an entry on level to in
+// dual stream mode.
+func consoleWriter(level Level) io.Writer {
+	if level >= LvlWarn {
+		return globStderr
+	}
+	return globStdout
+}
+
+// UseContainerPreset configures llog for applications running in a
+// container, such as in Docker or Kubernetes, where the runtime collects
+// stdout and adds timestamps. The log file is closed, dual stream mode is
+// disabled and entries are written to stdout in the JSON format, see
+// FormatJSON, without time but with the file and line:
+//
+//	{"schema":1,"level":"INFO","file":"main.go","line":23,"message":"message"}
+func UseContainerPreset() {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	if globFile != nil {
+		closeLogFile(globFile)
+		globFile = nil
+	}
+	log.SetFlags(log.Lshortfile)
+	log.SetOutput(os.Stdout)
+	globDualStream = false
+	globFileOptions.format = FormatJSON
+	updateOutput()
+}
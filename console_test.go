@@ -0,0 +1,100 @@
+// Unit tests for console
+package llog
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDualStream(t *testing.T) {
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	globStdout, globStderr = stdout, stderr
+	defer func() { globStdout, globStderr = os.Stdout, os.Stderr }()
+	SetLevel(LvlInfo)
+	SetDualStream(true)
+	defer SetDualStream(false)
+
+	Info("info")
+	Warn("warn")
+	Error("error")
+	Audit("audit")
+
+	if !strings.Contains(stdout.String(), "INFO - info") || strings.Count(stdout.String(), "\n") != 1 {
+		t.Fatalf("Stdout not correct: %s", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "WARN - warn") || !strings.Contains(stderr.String(), "ERROR - error") ||
+		!strings.Contains(stderr.String(), "AUDIT - audit") || strings.Count(stderr.String(), "\n") != 3 {
+		t.Fatalf("Stderr not correct: %s", stderr.String())
+	}
+
+	// Log file has precedence
+	logFileName := "dualstreamlog.txt"
+	os.Remove(logFileName)
+	defer os.Remove(logFileName)
+	if err := SetFile(logFileName, 1024); err != nil {
+		t.Fatalf("Unable to log to file. Reason: %s", err)
+	}
+	Error("to file")
+	Close()
+	content, _ := os.ReadFile(logFileName)
+	if !strings.Contains(string(content), "ERROR - to file") || strings.Contains(stderr.String(), "to file") {
+		t.Fatalf("Entry not written to log file: %s", content)
+	}
+}
+
+func TestContainerPreset(t *testing.T) {
+	logFileName := "containerlog.txt"
+	os.Remove(logFileName)
+	defer os.Remove(logFileName)
+	if err := SetFile(logFileName, 1024); err != nil {
+		t.Fatalf("Unable to log to file. Reason: %s", err)
+	}
+	UseContainerPreset()
+	defer func() {
+		log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
+		log.SetOutput(os.Stderr)
+		SetFormat(FormatText)
+	}()
+	if log.Writer() != os.Stdout {
+		t.Fatal("Output not set to stdout")
+	}
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	SetLevel(LvlInfo)
+	Info("in container")
+
+	if buffer.String() != `{"schema":1,"level":"INFO","file":"console_test.go","line":67,"message":"in container"}`+"\n" {
+		t.Fatalf("Entry not written in container format: %s", buffer.String())
+	}
+	content, _ := os.ReadFile(logFileName)
+	if strings.Contains(string(content), "in container") {
+		t.Fatalf("Entry written to log file: %s", content)
+	}
+}
+
+func TestDualStreamWriteBatch(t *testing.T) {
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	globStdout, globStderr = stdout, stderr
+	defer func() { globStdout, globStderr = os.Stdout, os.Stderr }()
+	SetLevel(LvlInfo)
+	SetDualStream(true)
+	defer SetDualStream(false)
+
+	WriteBatch([]Entry{
+		{Level: LvlInfo, Message: "info 1"},
+		{Level: LvlInfo, Message: "info 2"},
+		{Level: LvlError, Message: "error"},
+		{Level: LvlInfo, Message: "info 3"},
+	})
+
+	if out := stdout.String(); !strings.Contains(out, "INFO - info 1\n") || !strings.Contains(out, "INFO - info 3\n") ||
+		strings.Count(out, "\n") != 3 {
+		t.Fatalf("Stdout not correct: %s", out)
+	}
+	if !strings.Contains(stderr.String(), "ERROR - error\n") || strings.Count(stderr.String(), "\n") != 1 {
+		t.Fatalf("Stderr not correct: %s", stderr.String())
+	}
+}
@@ -0,0 +1,60 @@
+package llog
+
+import "context"
+
+// globTraceExtractor returns the trace and span ID in a context or is
+// nil if no extractor
+var globTraceExtractor func(ctx context.Context) (traceID, spanID string)
+
+// SetTraceExtractor sets a function returning the trace and span ID of
+// the span in a context, if any. Entries written by a logger created
+// with WithContext get the fields trace_id and span_id, so that they can
+// be correlated with distributed traces. llog has no dependency to
+// OpenTelemetry, so the extractor is typically:
+//
+//	llog.SetTraceExtractor(func(ctx context.Context) (string, string) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		if !sc.IsValid() {
+//			return "", ""
+//		}
+//		return sc.TraceID().String(), sc.SpanID().String()
+//	})
+//
+// Empty IDs are not written. A nil extractor disables the fields.
+func SetTraceExtractor(extractor func(ctx context.Context) (traceID, spanID string)) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globTraceExtractor = extractor
+	updateOutput()
+}
+
+// WithContext returns a logger writing entries with the trace and span
+// ID of the span in ctx, see SetTraceExtractor.
+func WithContext(ctx context.Context) *Logger {
+	return globLogger.WithContext(ctx)
+}
+
+// WithContext returns a copy of the logger writing entries with the
+// trace and span ID of the span in ctx, see SetTraceExtractor.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	c := *l
+	c.ctx = ctx
+	return &c
+}
+
+// traceFields returns the trace and span ID fields of the context of
+// the logger, if any.
+func (l *Logger) traceFields(extractor func(ctx context.Context) (string, string)) []Field {
+	if l.ctx == nil || extractor == nil {
+		return nil
+	}
+	traceID, spanID := extractor(l.ctx)
+	var fields []Field
+	if traceID != "" {
+		fields = append(fields, Field{Key: "trace_id", Value: traceID})
+	}
+	if spanID != "" {
+		fields = append(fields, Field{Key: "span_id", Value: spanID})
+	}
+	return fields
+}
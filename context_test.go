@@ -0,0 +1,44 @@
+// Unit tests for context
+package llog
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+type spanKey struct{}
+
+func TestWithContext(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+
+	ctx := context.WithValue(context.Background(), spanKey{}, [2]string{"4bf92f3577b34da6", "00f067aa"})
+	WithContext(ctx).Info("no extractor")
+	SetTraceExtractor(func(ctx context.Context) (string, string) {
+		ids, _ := ctx.Value(spanKey{}).([2]string)
+		return ids[0], ids[1]
+	})
+	defer SetTraceExtractor(nil)
+	WithContext(ctx).Info("in span")
+	WithContext(context.Background()).Info("no span")
+	WithWorker("conn-42").WithContext(ctx).Warn("worker in span")
+
+	output := buffer.String()
+	if !strings.Contains(output, "INFO - in span | trace_id=4bf92f3577b34da6 span_id=00f067aa\n") {
+		t.Fatalf("Trace fields not written: %s", output)
+	}
+	entries := readEntries(t, buffer)
+	if len(entries) != 4 || entries[0].Fields != nil || entries[2].Fields != nil {
+		t.Fatalf("Fields written without span: %v", entries)
+	}
+	if entries[3].Worker != "conn-42" || len(entries[3].Fields) != 2 ||
+		entries[3].Fields[0].Value != "4bf92f3577b34da6" {
+		t.Fatalf("Worker and trace fields not correct: %v", entries[3])
+	}
+}
@@ -0,0 +1,189 @@
+package llog
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// CrashDump writes a crash dump file when Panic, PanicErr or Must is
+// called, and on other panics in functions deferring RecoverCrash, for
+// post-mortem debugging of crashes in the field. The crash dump contains
+// the panic message, the stacks of all goroutines, the most recent
+// entries and runtime and memory statistics. Crash dumps are encrypted if
+// encryption is set with SetFileEncryption. Use Decrypt to read them.
+type CrashDump struct {
+	// Dir is the directory of the crash dump files, which are named
+	// crash-20060102-150405.000.txt. Default is the directory of the log
+	// file set with SetFile, or the current directory.
+	Dir string
+	// Recent is the number of recent entries included. Default is 100.
+	Recent int
+
+	handler entryHandler
+	mutex   sync.Mutex // protects all below
+	recent  *entryRing
+}
+
+// globCrashDump is the crash dump or nil if none
+var globCrashDump *CrashDump
+
+// globPanicMsg is the message of the latest panic written by llog, so
+// that RecoverCrash doesn't write it again
+var globPanicMsg atomic.Pointer[string]
+
+// maxDumpStack is the max size of the stacks of all goroutines in a
+// crash dump
+const maxDumpStack = 64 * 1024 * 1024
+
+// SetCrashDump writes crash dump files, see CrashDump. A nil dump stops
+// writing crash dump files.
+//
+// Errors when writing are reported to the error handler, see
+// SetErrorHandler.
+func SetCrashDump(dump *CrashDump) {
+	if dump != nil {
+		if dump.Recent == 0 {
+			dump.Recent = 100
+		}
+		dump.recent = newEntryRing(dump.Recent)
+		dump.handler.handle = dump.handle
+	}
+
+	globMutex.Lock()
+	old := globCrashDump
+	globCrashDump = dump
+	globMutex.Unlock()
+
+	if old != nil {
+		removeHandler(&old.handler)
+	}
+	if dump != nil {
+		addHandler(&dump.handler)
+	}
+}
+
+// RecoverCrash writes a log on panic level, and a crash dump if set with
+// SetCrashDump, when the calling goroutine panics, and then continues
+// the panic. Defer it first in main and in other goroutines:
+//
+//	func main() {
+//		defer llog.RecoverCrash()
+//		...
+//	}
+//
+// Panics from Panic, PanicErr and Must are not written again.
+func RecoverCrash() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	msg := fmt.Sprint(r)
+	if p := globPanicMsg.Load(); p == nil || *p != msg {
+		if LvlPanic >= levelSet() {
+			globLogger.output(2, LvlPanic, msgPlain, "panic: "+msg, nil)
+		}
+		syncLog()
+		panicked(msg)
+	}
+	panic(r)
+}
+
+// panicked shall be called after an entry on panic level is written, if
+// the panic level is written, and the log is synced, just before panic()
+// is called with msg.
+func panicked(msg string) {
+	globPanicMsg.Store(&msg)
+	globMutex.Lock()
+	dump := globCrashDump
+	logFile := globFile
+	aead := globFileOptions.aead
+	globMutex.Unlock()
+	if dump == nil {
+		return
+	}
+	dir := dump.Dir
+	if dir == "" && logFile != nil {
+		dir = filepath.Dir(logFile.name)
+	}
+	if err := dump.write(dir, msg, aead); err != nil {
+		reportError(fmt.Errorf("llog: unable to write crash dump: %w", err))
+	}
+}
+
+// handle is called with each entry written to the log.
+func (d *CrashDump) handle(e *Entry) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.recent.add(*e)
+}
+
+// write writes a crash dump file in dir, encrypted if aead is not nil.
+func (d *CrashDump) write(dir, msg string, aead cipher.AEAD) error {
+	t := now()
+	var w bytes.Buffer
+	fmt.Fprintf(&w, "Crash dump written by llog at %s\n\n", t.Format("2006-01-02 15:04:05.000 -0700"))
+	fmt.Fprintf(&w, "Panic: %s\n\n", msg)
+
+	fmt.Fprintf(&w, "Goroutines:\n\n%s\n", allStacks())
+
+	d.mutex.Lock()
+	recent := d.recent.recent()
+	d.mutex.Unlock()
+	fmt.Fprintf(&w, "Recent entries:\n\n")
+	for _, e := range recent {
+		fmt.Fprintf(&w, "%s\n", e)
+	}
+
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	fmt.Fprintf(&w, "\nRuntime:\n\n")
+	fmt.Fprintf(&w, "Version:      %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&w, "CPUs:         %d\n", runtime.NumCPU())
+	fmt.Fprintf(&w, "Goroutines:   %d\n", runtime.NumGoroutine())
+	fmt.Fprintf(&w, "Alloc:        %d\n", m.Alloc)
+	fmt.Fprintf(&w, "TotalAlloc:   %d\n", m.TotalAlloc)
+	fmt.Fprintf(&w, "Sys:          %d\n", m.Sys)
+	fmt.Fprintf(&w, "HeapInuse:    %d\n", m.HeapInuse)
+	fmt.Fprintf(&w, "HeapObjects:  %d\n", m.HeapObjects)
+	fmt.Fprintf(&w, "StackInuse:   %d\n", m.StackInuse)
+	fmt.Fprintf(&w, "NumGC:        %d\n", m.NumGC)
+	fmt.Fprintf(&w, "PauseTotalNs: %d\n", m.PauseTotalNs)
+
+	p := w.Bytes()
+	if aead != nil {
+		// Protected as the log files, since the entries might contain
+		// sensitive data
+		p = encryptRecord(aead, p)
+	}
+	name := filepath.Join(dir, "crash-"+t.Format("20060102-150405.000")+".txt")
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(p)
+	if syncErr := file.Sync(); err == nil {
+		err = syncErr
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// allStacks returns the stacks of all goroutines.
+func allStacks() []byte {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= maxDumpStack {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
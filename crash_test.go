@@ -0,0 +1,143 @@
+// Unit tests for crash
+package llog
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recoverPanic calls f and returns the value it panics with.
+func recoverPanic(f func()) (r interface{}) {
+	defer func() { r = recover() }()
+	f()
+	return nil
+}
+
+func TestCrashDump(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	clock := time.Date(2001, 2, 3, 4, 5, 6, 0, time.Local)
+	SetClock(func() time.Time { return clock })
+	defer SetClock(nil)
+	dir := t.TempDir()
+	SetCrashDump(&CrashDump{Dir: dir, Recent: 2})
+	defer SetCrashDump(nil)
+
+	Info("first")
+	Info("second")
+	if r := recoverPanic(func() { Panic("out of %s", "memory") }); r != "out of memory" {
+		t.Fatalf("Wrong panic %v", r)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "crash-20010203-040506.000.txt"))
+	if err != nil {
+		t.Fatalf("Crash dump not written. Reason: %s", err)
+	}
+	dump := string(content)
+	for _, want := range []string{"Panic: out of memory\n", "goroutine ", "TestCrashDump",
+		"INFO - second\n", "PANIC - out of memory\n", "HeapInuse:"} {
+		if !strings.Contains(dump, want) {
+			t.Fatalf("%q missing in crash dump:\n%s", want, dump)
+		}
+	}
+	if strings.Contains(dump, "INFO - first") {
+		t.Fatalf("Only the recent entries shall be in the crash dump:\n%s", dump)
+	}
+
+	// Other panics are written by RecoverCrash
+	clock = clock.Add(time.Second)
+	r := recoverPanic(func() {
+		defer RecoverCrash()
+		var m map[string]int
+		m["x"] = 1
+	})
+	if r == nil {
+		t.Fatalf("Panic not continued by RecoverCrash")
+	}
+	content, err = os.ReadFile(filepath.Join(dir, "crash-20010203-040507.000.txt"))
+	if err != nil || !strings.Contains(string(content), "Panic: assignment to entry in nil map") {
+		t.Fatalf("Crash dump not written by RecoverCrash. Reason: %v\n%s", err, content)
+	}
+	if !strings.Contains(buffer.String(), "PANIC - panic: assignment to entry in nil map\n") {
+		t.Fatalf("Panic not logged: %s", buffer.String())
+	}
+
+	// Panics from llog are not written again
+	clock = clock.Add(time.Second)
+	recoverPanic(func() {
+		defer RecoverCrash()
+		Must(os.ErrNotExist, "config")
+	})
+	if files, _ := os.ReadDir(dir); len(files) != 3 || strings.Count(buffer.String(), "config") != 1 {
+		t.Fatalf("Panic written twice: %d files\n%s", len(files), buffer.String())
+	}
+}
+
+func TestCrashDumpEncrypted(t *testing.T) {
+	log.SetOutput(new(bytes.Buffer))
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	key := bytes.Repeat([]byte{7}, 32)
+	if err := SetFileEncryption(key); err != nil {
+		t.Fatalf("Unable to set encryption. Reason: %s", err)
+	}
+	defer SetFileEncryption(nil)
+	dir := t.TempDir()
+	SetCrashDump(&CrashDump{Dir: dir})
+	defer SetCrashDump(nil)
+
+	Info("customer 4711")
+	recoverPanic(func() { Panic("crash") })
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Fatalf("Expected one crash dump, got %d", len(files))
+	}
+	content, _ := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	if bytes.Contains(content, []byte("customer 4711")) {
+		t.Fatalf("Crash dump not encrypted")
+	}
+	var plain bytes.Buffer
+	if err := Decrypt(&plain, bytes.NewReader(content), key); err != nil ||
+		!strings.Contains(plain.String(), "INFO - customer 4711") {
+		t.Fatalf("Unable to decrypt crash dump. Reason: %v", err)
+	}
+}
+
+func TestCrashDumpAboveLevel(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlAudit)
+	defer SetLevel(LvlInfo)
+	clock := time.Date(2001, 2, 3, 4, 5, 6, 0, time.Local)
+	SetClock(func() time.Time { return clock })
+	defer SetClock(nil)
+	dir := t.TempDir()
+	SetCrashDump(&CrashDump{Dir: dir})
+	defer SetCrashDump(nil)
+
+	recoverPanic(func() { Panic("out of %s", "memory") })
+	content, err := os.ReadFile(filepath.Join(dir, "crash-20010203-040506.000.txt"))
+	if err != nil || !strings.Contains(string(content), "Panic: out of memory\n") {
+		t.Fatalf("Crash dump not written above the panic level. Reason: %v\n%s", err, content)
+	}
+
+	clock = clock.Add(time.Second)
+	recoverPanic(func() {
+		defer RecoverCrash()
+		var m map[string]int
+		m["x"] = 1
+	})
+	if _, err := os.Stat(filepath.Join(dir, "crash-20010203-040507.000.txt")); err != nil {
+		t.Fatalf("Crash dump not written by RecoverCrash above the panic level. Reason: %s", err)
+	}
+	if buffer.Len() != 0 {
+		t.Fatalf("Panic level written above level: %s", buffer)
+	}
+}
@@ -0,0 +1,122 @@
+package llog
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+// SetFileEncryption encrypts log files, and thereby their backups, with
+// AES-GCM using key, which must be 16, 24 or 32 bytes long (AES-128,
+// AES-192 or AES-256). Only files opened after this call, with SetFile or
+// SetAuditFile, are encrypted. Use Decrypt to read an encrypted log file.
+//
+// A nil key disables encryption for files opened after this call.
+func SetFileEncryption(key []byte) error {
+	var aead cipher.AEAD
+	if key != nil {
+		var err error
+		aead, err = newCipher(key)
+		if err != nil {
+			return err
+		}
+	}
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globFileOptions.aead = aead
+	return nil
+}
+
+// Decrypt decrypts a log file encrypted with the key set in
+// SetFileEncryption. The decrypted log is written to dst.
+func Decrypt(dst io.Writer, src io.Reader, key []byte) error {
+	aead, err := newCipher(key)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, &decryptReader{r: src, aead: aead})
+	return err
+}
+
+// newCipher creates an AES-GCM cipher.
+func newCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptRecord encrypts p into a record which is the length of the
+// rest of the record (4 bytes big endian), the nonce and the ciphertext.
+func encryptRecord(aead cipher.AEAD, p []byte) []byte {
+	size := aead.NonceSize() + len(p) + aead.Overhead()
+	record := make([]byte, 4+aead.NonceSize(), 4+size)
+	binary.BigEndian.PutUint32(record, uint32(size))
+	nonce := record[4:]
+	rand.Read(nonce)
+	return aead.Seal(record, nonce, p, nil)
+}
+
+// decryptReader reads records created by encryptRecord and returns the
+// plain text.
+type decryptReader struct {
+	r    io.Reader
+	aead cipher.AEAD
+	buf  []byte // Decrypted data not yet read
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		var header [4]byte
+		if _, err := io.ReadFull(d.r, header[:]); err != nil {
+			return 0, err // io.EOF if no more records
+		}
+		record := make([]byte, binary.BigEndian.Uint32(header[:]))
+		if _, err := io.ReadFull(d.r, record); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		if len(record) < d.aead.NonceSize() {
+			return 0, errors.New("llog: invalid encrypted record")
+		}
+		nonce := record[:d.aead.NonceSize()]
+		var err error
+		d.buf, err = d.aead.Open(record[len(nonce):len(nonce)], nonce, record[len(nonce):], nil)
+		if err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+// logReader reads a possibly encrypted log file.
+type logReader struct {
+	io.Reader
+	file *os.File
+}
+
+func (r *logReader) Close() error {
+	return r.file.Close()
+}
+
+// openLogReader opens a log file for reading. If aead is not nil the
+// file is decrypted while read.
+func openLogReader(fileName string, aead cipher.AEAD) (io.ReadCloser, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if aead == nil {
+		return file, nil
+	}
+	return &logReader{Reader: &decryptReader{r: file, aead: aead}, file: file}, nil
+}
@@ -0,0 +1,99 @@
+// Unit tests for log file encryption
+package llog
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFileEncryption(t *testing.T) {
+	logFileName := "cryptlog.txt"
+	backupFileName := logFileName + ".1"
+	key := []byte("0123456789abcdef")
+	os.Remove(logFileName)
+	os.Remove(backupFileName)
+	if err := SetFileEncryption(key); err != nil {
+		t.Fatalf("Unable to set encryption. Reason: %s", err)
+	}
+	defer SetFileEncryption(nil)
+	SetLevel(LvlInfo)
+	err := SetFile(logFileName, 2) // Max size 2 KB
+	if err != nil {
+		t.Fatalf("Unable to log to file. Reason: %s", err)
+	}
+	for i := 0; i < 100; i++ {
+		Info("customer %d", i)
+	}
+	log.SetOutput(os.Stderr)
+	globFile.Close()
+	globFile = nil
+	updateOutput()
+
+	for _, fileName := range []string{logFileName, backupFileName} {
+		content, _ := os.ReadFile(fileName)
+		if bytes.Contains(content, []byte("customer")) {
+			t.Fatalf("File %s is not encrypted", fileName)
+		}
+	}
+	file, _ := os.Open(logFileName)
+	defer file.Close()
+	decrypted := new(bytes.Buffer)
+	if err := Decrypt(decrypted, file, key); err != nil {
+		t.Fatalf("Unable to decrypt. Reason: %s", err)
+	}
+	if !strings.Contains(decrypted.String(), "INFO - customer 99") {
+		t.Fatalf("Decrypted log is not correct:\n%s", decrypted)
+	}
+	file.Seek(0, 0)
+	if err := Decrypt(decrypted, file, []byte("fedcba9876543210")); err == nil {
+		t.Fatalf("Decrypt with wrong key shall fail")
+	}
+
+	// Cleanup
+	os.Remove(logFileName)
+	os.Remove(backupFileName)
+}
+
+func TestInvalidEncryptionKey(t *testing.T) {
+	if err := SetFileEncryption([]byte("short")); err == nil {
+		t.Fatalf("Invalid key shall give an error")
+	}
+}
+
+func TestEncryptedAuditChain(t *testing.T) {
+	auditFileName := "cryptaudit.txt"
+	key := []byte("0123456789abcdef")
+	os.Remove(auditFileName)
+	SetFileEncryption(key)
+	SetAuditChain(key)
+	defer SetFileEncryption(nil)
+	defer SetAuditChain(nil)
+	for i := 0; i < 2; i++ { // Second time the chain is resumed
+		if err := SetAuditFile(auditFileName, 100); err != nil {
+			t.Fatalf("Unable to audit log to file. Reason: %s", err)
+		}
+		Audit("entry %d", i)
+		globAuditFile.Close()
+	}
+
+	file, _ := os.Open(auditFileName)
+	decrypted := new(bytes.Buffer)
+	err := Decrypt(decrypted, file, key)
+	file.Close()
+	if err != nil {
+		t.Fatalf("Unable to decrypt. Reason: %s", err)
+	}
+	os.WriteFile(auditFileName, decrypted.Bytes(), 0666)
+	if err := VerifyAudit(auditFileName, key); err != nil {
+		t.Fatalf("Verification failed: %s\n%s", err, decrypted)
+	}
+
+	// Cleanup
+	globAuditFile = nil
+	globAuditLogger = nil
+	updateOutput()
+	os.Remove(auditFileName)
+}
@@ -0,0 +1,149 @@
+package llog
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// Entry is a log entry.
+type Entry struct {
+	// Time when the entry was written
+	Time time.Time
+	// Seq is the sequence number of the entry or 0 if sequence numbers
+	// are not written, see SetSequenceNumbers
+	Seq uint64
+	// Worker is the worker tag of the entry or empty if none, see
+	// WithWorker
+	Worker string
+	// Level of the entry
+	Level Level
+	// File is the source file, with full path, where the entry was
+	// written and Line is the line in that file
+	File string
+	Line int
+	// Message of the entry
+	Message string
+	// Fields of the entry, such as the trace_id of the span the entry
+	// was written in, or nil if none
+	Fields []Field
+
+	wait entryWait // how long sinks may block, see WithMaxWait
+}
+
+// Field is a key and value attached to an entry. Fields are written
+// after the message:
+//
+//	2009/01/23 01:23:23 file.go:23: INFO - message | key1=value1 key2=value2
+//
+// Keys and values shall not contain spaces.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// entryJSON is the JSON encoding of an entry
+type entryJSON struct {
+	Schema  int               `json:"schema,omitempty"`
+	Time    *time.Time        `json:"time,omitempty"`
+	Seq     uint64            `json:"seq,omitempty"`
+	Worker  string            `json:"worker,omitempty"`
+	Level   string            `json:"level"`
+	File    string            `json:"file,omitempty"`
+	Line    int               `json:"line,omitempty"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// MarshalJSON encodes the entry as a JSON object, for example:
+//
+//	{"time":"2009-01-23T01:23:23.123456+01:00","level":"INFO","file":"/src/file.go",
+//	"line":23,"message":"message","fields":{"key":"value"}}
+func (e Entry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newEntryJSON(&e))
+}
+
+// newEntryJSON returns the JSON encoding of an entry.
+func newEntryJSON(e *Entry) entryJSON {
+	j := entryJSON{Time: &e.Time, Seq: e.Seq, Worker: e.Worker, Level: e.Level.String(),
+		File: e.File, Line: e.Line, Message: e.Message}
+	if len(e.Fields) > 0 {
+		j.Fields = make(map[string]string, len(e.Fields))
+		for _, f := range e.Fields {
+			j.Fields[f.Key] = f.Value
+		}
+	}
+	return j
+}
+
+// String returns the entry on the default log format, without trailing
+// newline.
+func (e Entry) String() string {
+	b := appendEntry(nil, &e, "", log.Ldate|log.Ltime|log.Lshortfile)
+	return string(b[:len(b)-1])
+}
+
+// entryHandler handles entries written to the log
+type entryHandler struct {
+	handle func(e *Entry)
+}
+
+// globHandlers are called with each entry written to the log. A new slice
+// is created when handlers are added or removed.
+var globHandlers []*entryHandler
+
+// addHandler makes h be called with each entry written to the log.
+func addHandler(h *entryHandler) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	handlers := make([]*entryHandler, 0, len(globHandlers)+1)
+	globHandlers = append(append(handlers, globHandlers...), h)
+	updateOutput()
+}
+
+// removeHandler removes a handler added with addHandler.
+func removeHandler(h *entryHandler) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	handlers := make([]*entryHandler, 0, len(globHandlers))
+	for _, handler := range globHandlers {
+		if handler != h {
+			handlers = append(handlers, handler)
+		}
+	}
+	globHandlers = handlers
+	updateOutput()
+}
+
+// entryRing keeps the most recent entries.
+type entryRing struct {
+	entries []Entry
+	next    int // index of next entry to write
+	full    bool
+}
+
+// newEntryRing creates a ring keeping the size most recent entries.
+func newEntryRing(size int) *entryRing {
+	return &entryRing{entries: make([]Entry, size)}
+}
+
+// add adds an entry, replacing the oldest entry if the ring is full.
+func (r *entryRing) add(e Entry) {
+	if len(r.entries) == 0 {
+		return
+	}
+	e.wait = entryWait{} // The context is not kept
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// recent returns the entries, oldest first.
+func (r *entryRing) recent() []Entry {
+	if !r.full {
+		return append([]Entry(nil), r.entries[:r.next]...)
+	}
+	return append(append([]Entry(nil), r.entries[r.next:]...), r.entries[:r.next]...)
+}
@@ -0,0 +1,32 @@
+package llog
+
+import "sync"
+
+// globErrorHandler is called when llog fails internally or nil
+var globErrorHandler func(error)
+
+// globErrorMutex protects globErrorHandler. Not using globMutex since
+// errors are reported while log files are locked.
+var globErrorMutex = &sync.Mutex{}
+
+// SetErrorHandler sets a function that is called when llog fails
+// internally, for example when a log file can't be wrapped. Use it to
+// detect that logging is broken. By default such errors are ignored.
+//
+// The handler is called synchronously and must not write log entries
+// using llog.
+func SetErrorHandler(handler func(error)) {
+	globErrorMutex.Lock()
+	defer globErrorMutex.Unlock()
+	globErrorHandler = handler
+}
+
+// reportError calls the error handler, if any.
+func reportError(err error) {
+	globErrorMutex.Lock()
+	handler := globErrorHandler
+	globErrorMutex.Unlock()
+	if handler != nil {
+		handler(err)
+	}
+}
@@ -0,0 +1,94 @@
+package llog
+
+import "context"
+
+// EventCode is a stable code identifying an event, for example
+// "DB001", so that alerting can key on the code instead of the message,
+// which may change between releases. Codes shall not contain spaces.
+type EventCode string
+
+// WithEvent returns a logger writing entries with the event code in the
+// field event:
+//
+//	llog.WithEvent(EvDBConnLost).Error("DB connection lost: %v", err)
+//	// 2009/01/23 01:23:23 file.go:23: ERROR - DB connection lost: timeout | event=DB001
+func WithEvent(code EventCode) *Logger {
+	return globLogger.WithEvent(code)
+}
+
+// WithEvent returns a copy of the logger writing entries with the event
+// code in the field event. An empty code writes no event field.
+func (l *Logger) WithEvent(code EventCode) *Logger {
+	c := *l
+	c.event = code
+	return &c
+}
+
+// globEventLevels are the levels set with SetEventLevel. The map is
+// replaced, not modified, since it is shared with outputConfig.
+var globEventLevels map[EventCode]Level
+
+// SetEventLevel writes entries with the event code on level instead of
+// the level of the call, so that a noisy event can be demoted, or an
+// important event promoted, without changing the calls or the level set
+// with SetLevel:
+//
+//	llog.SetEventLevel(EvHealthCheck, llog.LvlTrace)
+//
+// Entries on panic and audit level are not affected. Level 0 removes
+// the override.
+func SetEventLevel(code EventCode, level Level) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	levels := make(map[EventCode]Level, len(globEventLevels)+1)
+	for c, l := range globEventLevels {
+		levels[c] = l
+	}
+	if level == 0 {
+		delete(levels, code)
+	} else {
+		levels[code] = level
+	}
+	globEventLevels = levels
+	updateOutput()
+}
+
+// eventLevel returns the level to write an entry of the logger on,
+// given the level of the call.
+func (l *Logger) eventLevel(level Level) Level {
+	if l.event == "" {
+		return level
+	}
+	if override, ok := globOutput.Load().eventLevels[l.event]; ok {
+		return override
+	}
+	return level
+}
+
+// Event returns the event code of the entry or empty if none, see
+// WithEvent.
+func (e Entry) Event() EventCode {
+	for _, f := range e.Fields {
+		if f.Key == "event" {
+			return EventCode(f.Value)
+		}
+	}
+	return ""
+}
+
+// fields returns the fields written with each entry of the logger, the
+// event code and request ID followed by the trace fields.
+func (l *Logger) fields(extractor func(ctx context.Context) (string, string)) []Field {
+	fields := l.traceFields(extractor)
+	if l.event == "" && l.requestID == "" {
+		return fields
+	}
+	var own []Field
+	if l.event != "" {
+		own = append(own, Field{Key: "event", Value: string(l.event)})
+	}
+	if l.requestID != "" {
+		own = append(own, Field{Key: "request_id", Value: l.requestID})
+	}
+	return append(own, fields...)
+}
@@ -0,0 +1,64 @@
+// Unit tests for event
+package llog
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+const evTest EventCode = "TEST001"
+
+func TestWithEvent(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+
+	WithEvent(evTest).Error("connection lost: %v", "timeout")
+	WithEvent("").Info("no event")
+	SetTraceExtractor(func(ctx context.Context) (string, string) {
+		return "4bf92f3577b34da6", ""
+	})
+	defer SetTraceExtractor(nil)
+	WithWorker("w1").WithContext(context.Background()).WithEvent(evTest).Warn("in span")
+
+	output := buffer.String()
+	if !strings.Contains(output, "ERROR - connection lost: timeout | event=TEST001\n") ||
+		!strings.Contains(output, "[w1] WARN - in span | event=TEST001 trace_id=4bf92f3577b34da6\n") {
+		t.Fatalf("Event code not written: %s", output)
+	}
+	entries := readEntries(t, buffer)
+	if len(entries) != 3 || entries[0].Event() != evTest || entries[1].Event() != "" ||
+		entries[2].Event() != evTest {
+		t.Fatalf("Event code not parsed: %v", entries)
+	}
+}
+
+func TestSetEventLevel(t *testing.T) {
+	skipInRelease(t)
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+
+	const evNoisy, evImportant EventCode = "NOISY", "IMPORTANT"
+	SetEventLevel(evNoisy, LvlTrace)
+	SetEventLevel(evImportant, LvlError)
+	WithEvent(evNoisy).Info("demoted")
+	WithEvent(evNoisy).Check(os.ErrClosed, "demoted check")
+	WithEvent(evImportant).Debug("promoted")
+	Info("no event")
+	SetEventLevel(evNoisy, 0)
+	WithEvent(evNoisy).Info("restored")
+	SetEventLevel(evImportant, 0)
+
+	entries := readEntries(t, buffer)
+	if len(entries) != 3 || entries[0].Level != LvlError || entries[0].Message != "promoted" ||
+		entries[1].Level != LvlInfo || entries[2].Message != "restored" {
+		t.Fatalf("Event levels not applied: %v", entries)
+	}
+}
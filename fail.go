@@ -0,0 +1,35 @@
+package llog
+
+import "time"
+
+// FailMode decides what to do with an entry that can't be written to
+// the log file, for example because the disk is full. Writing to the
+// log file is resumed as soon as a write succeeds again. A log file that
+// can't be reopened, for example after a wrap, is opened again at most
+// once per second until it succeeds.
+type FailMode int
+
+const (
+	// FailStderr writes the entry to stderr instead (default)
+	FailStderr FailMode = iota
+	// FailDrop drops the entry. Dropped entries are counted, see Stats.
+	FailDrop
+	// FailBlock retries to write the entry, reopening the file if
+	// needed, until it succeeds. All logging is blocked meanwhile.
+	FailBlock
+)
+
+// failRetryDelay is the delay before the first retry in FailBlock mode.
+// The delay is doubled for each retry up to failRetryMaxDelay.
+const failRetryDelay = 10 * time.Millisecond
+
+// failRetryMaxDelay is the max delay between retries in FailBlock mode
+const failRetryMaxDelay = time.Second
+
+// SetFailMode decides what to do with entries that can't be written to
+// log files opened after this call.
+func SetFailMode(mode FailMode) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globFileOptions.failMode = mode
+}
@@ -0,0 +1,612 @@
+package llog
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fileOptions are options for log files.
+type fileOptions struct {
+	aead     cipher.AEAD // encrypts each entry or nil
+	locking  bool        // lock wrap against other processes
+	wrapMode WrapMode
+	// wrapOnOpen wraps non-empty log files when opened
+	wrapOnOpen bool
+	// wrapInterval is the max time a log file is written or 0
+	wrapInterval time.Duration
+	failMode     FailMode
+	sync         SyncPolicy
+	// bufferSize is the size of the write buffer, 0 if not buffered
+	bufferSize    int
+	flushInterval time.Duration
+	format        Format
+	// metadata writes a metadata record first in files in FormatJSON
+	metadata bool
+}
+
+// globFileOptions are the options given to log files when opened
+var globFileOptions = fileOptions{
+	wrapMode: WrapRename,
+	failMode: FailStderr,
+	sync:     SyncPolicy{Entries: 20},
+}
+
+// logFile is a log file that is wrapped when it exceeds a maximum size.
+// A logFile is used as output by the loggers in llog.
+type logFile struct {
+	fileOptions
+	name       string
+	file       *os.File // nil if the file could not be reopened after wrap
+	maxSizeKB  int
+	counter    int            // counting to know when log wrap should be checked
+	unsynced   int            // number of entries written since last sync
+	syncTimer  *time.Timer    // pending sync or nil
+	buf        *bufio.Writer  // write buffer or nil if not buffered
+	flushTimer *time.Timer    // pending flush or nil
+	chain      *hashChain     // hash chain sealing each entry or nil
+	header     func() string  // header written to each new file or nil
+	failing    bool           // true if last write failed
+	closed     bool           // true if closed, false if file is nil since it couldn't be reopened
+	reopened   time.Time      // time of the latest try to reopen the file
+	wraps      []time.Time    // times of the latest wraps, oldest first
+	started    time.Time      // when the current file was started
+	binary     *binaryEncoder // encoder if FormatBinary or nil
+	mutex      sync.Mutex     // protects all above
+}
+
+// openLogFile opens (or creates) a log file for appending.
+func openLogFile(fileName string, maxSizeKB int) (*logFile, error) {
+	globMutex.Lock()
+	options := globFileOptions
+	globMutex.Unlock()
+	return openLogFileOptions(fileName, maxSizeKB, options)
+}
+
+// openLogFileOptions opens a log file as openLogFile, with options.
+func openLogFileOptions(fileName string, maxSizeKB int, options fileOptions) (*logFile, error) {
+	file, err := os.OpenFile(fileName, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return nil, err
+	}
+	if options.format == FormatPretty {
+		options.format = FormatText // Only used for the console
+	}
+	f := &logFile{fileOptions: options, name: fileName, file: file, maxSizeKB: maxSizeKB,
+		started: now()}
+	if f.bufferSize > 0 {
+		f.buf = bufio.NewWriterSize(file, f.bufferSize)
+	}
+	if f.wrapOnOpen {
+		f.wrapIfNotEmpty()
+	}
+	return f, nil
+}
+
+// wrapIfNotEmpty wraps the log file if it isn't empty.
+func (f *logFile) wrapIfNotEmpty() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.locking {
+		unlock, err := f.lockWrap()
+		if err != nil {
+			reportError(fmt.Errorf("llog: unable to lock %s: %w", f.name, err))
+		} else {
+			defer unlock()
+		}
+	}
+	info, err := f.file.Stat()
+	if err != nil {
+		reportError(fmt.Errorf("llog: unable to check size of %s: %w", f.name, err))
+		return
+	}
+	if info.Size() > 0 {
+		f.wrap()
+	}
+}
+
+// Write writes to the log file. If the log file could not be reopened
+// after a wrap stderr is used instead. If the write fails the fail mode
+// decides what to do with the entry.
+func (f *logFile) Write(p []byte) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	n := len(p)
+	if f.chain != nil {
+		p = f.chain.seal(p)
+	}
+	if err := f.writeOrFail(p, p); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// writeEntry writes an entry to a log file in FormatBinary. The file of
+// the entry is written as with flags, see binaryEncoder.encode.
+func (f *logFile) writeEntry(e *Entry, flags int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	text := []byte(e.String() + "\n")
+	if f.file == nil && !f.closed {
+		f.resume()
+	}
+	if f.file == nil {
+		os.Stderr.Write(text)
+		return
+	}
+	f.writeOrFail(f.binary.encode(e, flags), text)
+	if f.failing {
+		// The file names defined might not have been written
+		f.binary.reset()
+	}
+}
+
+// writeOrFail writes p to the log file. If the write fails the fail mode
+// decides what to do, where text is p in the text format, written to
+// stderr in FailStderr mode. Mutex must be held.
+func (f *logFile) writeOrFail(p []byte, text []byte) error {
+	if f.file == nil && !f.closed {
+		f.resume()
+	}
+	err := f.write(p)
+	delay := failRetryDelay
+	for err != nil {
+		globStats.writeErrors.Add(1)
+		if !f.failing {
+			// Only report first failure to not flood the error handler
+			f.failing = true
+			reportError(fmt.Errorf("llog: unable to write to %s: %w", f.name, err))
+		}
+		switch f.failMode {
+		case FailStderr:
+			os.Stderr.Write(text)
+			return nil
+		case FailDrop:
+			globStats.dropped.Add(1)
+			return err
+		}
+		// FailBlock, retry on a reopened file in case the file is broken.
+		// Never written to stderr, even if the file can't be reopened.
+		time.Sleep(delay)
+		delay = min(2*delay, failRetryMaxDelay)
+		if f.file != nil {
+			f.file.Close()
+			f.file = nil
+		}
+		if err = f.openFile(); err == nil {
+			err = f.write(p)
+		}
+	}
+	f.failing = false
+	return nil
+}
+
+// write writes p to the log file, encrypted if an encryption key was
+// set when the file was opened. Mutex must be held.
+func (f *logFile) write(p []byte) error {
+	if f.file == nil {
+		_, err := os.Stderr.Write(p)
+		return err
+	}
+	if f.aead != nil {
+		p = encryptRecord(f.aead, p)
+	}
+	if f.buf == nil {
+		_, err := f.file.Write(p)
+		return err
+	}
+	if _, err := f.buf.Write(p); err != nil {
+		f.buf.Reset(f.file) // Drop buffered data that can't be written
+		return err
+	}
+	if f.flushInterval > 0 && f.flushTimer == nil {
+		f.flushTimer = time.AfterFunc(f.flushInterval, func() {
+			f.mutex.Lock()
+			defer f.mutex.Unlock()
+			f.flushTimer = nil
+			f.flush()
+		})
+	}
+	return nil
+}
+
+// Flush writes any buffered data to the log file.
+func (f *logFile) Flush() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.flush()
+}
+
+// flush writes any buffered data to the log file. Mutex must be held.
+func (f *logFile) flush() error {
+	if f.buf == nil || f.buf.Buffered() == 0 || f.file == nil {
+		return nil
+	}
+	err := f.buf.Flush()
+	if err != nil {
+		globStats.writeErrors.Add(1)
+		f.buf.Reset(f.file) // Drop buffered data that can't be written
+		reportError(fmt.Errorf("llog: unable to write to %s: %w", f.name, err))
+	}
+	return err
+}
+
+// startChain seals all entries written from now on with a hash chain
+// using key. If the file already contains a chain it is continued.
+func (f *logFile) startChain(key []byte) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	chain, err := resumeHashChain(f.name, key, f.aead)
+	if err != nil {
+		return err
+	}
+	f.chain = chain
+	if chain.prev == nil {
+		f.writeChainStart()
+	}
+	return nil
+}
+
+// maxWraps is the number of wraps remembered in the wrap history
+const maxWraps = 20
+
+// fileStarted records the wrap and writes the chain start and the header
+// at the top of the new log file. Mutex must be held.
+func (f *logFile) fileStarted() {
+	if len(f.wraps) == maxWraps {
+		f.wraps = f.wraps[1:]
+	}
+	f.started = now()
+	f.wraps = append(f.wraps, f.started)
+	if f.binary != nil {
+		f.binary.reset()
+	}
+	if f.chain != nil {
+		f.writeChainStart()
+	}
+	f.writeHeader()
+}
+
+// setHeader sets the header written at the top of each new log file and
+// writes it directly.
+func (f *logFile) setHeader(header func() string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.header = header
+	f.writeHeader()
+}
+
+// writeHeader writes the header, if any, or the metadata record if the
+// file is in FormatJSON. Mutex must be held.
+func (f *logFile) writeHeader() {
+	if f.file == nil {
+		return
+	}
+	var header string
+	if f.header != nil {
+		header = f.header()
+	}
+	var p []byte
+	switch {
+	case f.format == FormatJSON:
+		if header == "" && !f.metadata {
+			return
+		}
+		p = appendMetadata(nil, newFileMetadata(header))
+	case header == "":
+		return
+	default:
+		if !strings.HasSuffix(header, "\n") {
+			header += "\n"
+		}
+		p = []byte(header)
+		if f.binary != nil {
+			p = f.binary.text(header)
+		}
+	}
+	if err := f.write(p); err != nil {
+		reportError(fmt.Errorf("llog: unable to write to %s: %w", f.name, err))
+	}
+}
+
+// startBinary writes entries in FormatBinary from now on.
+func (f *logFile) startBinary() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.binary = &binaryEncoder{}
+}
+
+// writeChainStart writes the first entry of a chained file. Mutex must
+// be held.
+func (f *logFile) writeChainStart() {
+	if f.file == nil {
+		return
+	}
+	if err := f.write(f.chain.start()); err != nil {
+		reportError(fmt.Errorf("llog: unable to write to %s: %w", f.name, err))
+	}
+}
+
+// Sync commits the log file to stable storage.
+func (f *logFile) Sync() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	f.flush()
+	f.unsynced = 0
+	return f.file.Sync()
+}
+
+// Close closes the log file.
+func (f *logFile) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.syncTimer != nil {
+		f.syncTimer.Stop()
+		f.syncTimer = nil
+	}
+	if f.flushTimer != nil {
+		f.flushTimer.Stop()
+		f.flushTimer = nil
+	}
+	if f.file == nil {
+		return nil
+	}
+	f.flush()
+	err := f.file.Close()
+	f.file = nil
+	f.closed = true
+	return err
+}
+
+// entryWritten shall be called after each entry written to the log
+// file. The log file is synced according to the sync policy and wrapped
+// if needed.
+func (f *logFile) entryWritten(level Level) {
+	f.entriesWritten(1, level)
+}
+
+// entriesWritten shall be called after n entries, of at most level, are
+// written to the log file in one write.
+func (f *logFile) entriesWritten(n int, level Level) {
+	f.mutex.Lock() // For thread safety
+	defer f.mutex.Unlock()
+
+	if f.file == nil {
+		return
+	}
+	if level >= LvlError {
+		f.flush()
+	}
+	f.syncIfNeeded(n, level)
+	f.wrapIfNeeded(n)
+}
+
+// syncIfNeeded syncs the log file according to the sync policy, after n
+// entries of at most level are written. Mutex must be held.
+func (f *logFile) syncIfNeeded(n int, level Level) {
+	f.unsynced += n
+	// Buffered files are written when the buffer is full, not synced
+	// every few entries, see SetBuffering
+	if (f.sync.MinLevel > 0 && level >= f.sync.MinLevel) ||
+		(f.sync.Entries > 0 && f.unsynced >= f.sync.Entries && f.buf == nil) {
+		f.syncFile()
+		return
+	}
+	if f.sync.Interval > 0 && f.syncTimer == nil {
+		f.syncTimer = time.AfterFunc(f.sync.Interval, func() {
+			f.mutex.Lock()
+			defer f.mutex.Unlock()
+			f.syncTimer = nil
+			if f.file != nil && f.unsynced > 0 {
+				f.syncFile()
+			}
+		})
+	}
+}
+
+// syncFile commits the log file to stable storage. Mutex must be held.
+func (f *logFile) syncFile() {
+	f.flush()
+	f.unsynced = 0
+	if err := f.file.Sync(); err != nil {
+		reportError(fmt.Errorf("llog: unable to sync %s: %w", f.name, err))
+	}
+}
+
+// wrapIfNeeded wraps the log if maxSizeKB has exceeded, or the wrap
+// interval has passed, after n entries are written. To avoid file
+// accesses for every log entry the actual file check is only done every
+// 20th log write. Mutex must be held.
+func (f *logFile) wrapIfNeeded(n int) {
+	f.counter += n
+	expired := f.wrapInterval > 0 && now().Sub(f.started) >= f.wrapInterval
+	if expired {
+		f.started = now() // Not retried for each entry if the wrap fails
+	}
+	if f.counter < 20 && !expired {
+		return
+	}
+	f.counter = 0 // Reset counter
+	if f.locking {
+		unlock, err := f.lockWrap()
+		if err != nil {
+			reportError(fmt.Errorf("llog: unable to lock %s: %w", f.name, err))
+		} else {
+			defer unlock()
+		}
+		if f.reopenIfMoved() {
+			// Another process has wrapped the file
+			return
+		}
+	}
+	info, err := f.file.Stat()
+	if err != nil {
+		reportError(fmt.Errorf("llog: unable to check size of %s: %w", f.name, err))
+		return
+	}
+	size := info.Size()
+	if f.buf != nil {
+		size += int64(f.buf.Buffered())
+	}
+	if (size/1024) >= int64(f.maxSizeKB) || expired {
+		f.wrap()
+	}
+}
+
+// wrap moves the current log file to a backup file with suffix ".1" and
+// starts over on a new log file. Mutex must be held.
+func (f *logFile) wrap() {
+	f.flush()
+	if f.wrapMode == WrapCopyTruncate {
+		f.copyTruncate()
+		return
+	}
+	f.file.Close()
+	backupFileName := f.name + ".1"
+	// Another process, for example a virus scanner, might temporarily
+	// prevent the rename (on Windows)
+	err := retry(func() error {
+		err := os.Remove(backupFileName) // Remove backup if existing
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return os.Rename(f.name, backupFileName) // Make backup
+	})
+	f.reopen()
+	if err != nil {
+		// Continue on the existing log file, try again next check
+		reportError(fmt.Errorf("llog: unable to wrap %s: %w", f.name, err))
+		return
+	}
+	f.fileStarted()
+}
+
+// wrapRetries is the number of times a failed wrap operation is retried
+const wrapRetries = 5
+
+// wrapRetryDelay is the delay before the first retry. The delay is
+// doubled for each retry.
+const wrapRetryDelay = 10 * time.Millisecond
+
+// retry calls op until it succeeds, at most wrapRetries + 1 times.
+func retry(op func() error) error {
+	err := op()
+	delay := wrapRetryDelay
+	for i := 0; i < wrapRetries && err != nil; i++ {
+		time.Sleep(delay)
+		delay *= 2
+		err = op()
+	}
+	return err
+}
+
+// copyTruncate copies the current log file to a backup file with suffix
+// ".1" and truncates the log file. Mutex must be held.
+func (f *logFile) copyTruncate() {
+	if err := f.copyToBackup(); err != nil {
+		// Keep the log rather than losing it, try again next check
+		reportError(fmt.Errorf("llog: unable to wrap %s: %w", f.name, err))
+		return
+	}
+	if err := f.file.Truncate(0); err != nil {
+		reportError(fmt.Errorf("llog: unable to truncate %s: %w", f.name, err))
+		return
+	}
+	f.fileStarted()
+}
+
+// copyToBackup copies the log file to a backup file with suffix ".1".
+func (f *logFile) copyToBackup() error {
+	src, err := os.Open(f.name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	backup, err := os.Create(f.name + ".1")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(backup, src)
+	if closeErr := backup.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// reopen opens the log file again after it has been closed. Mutex must
+// be held.
+func (f *logFile) reopen() {
+	if err := retry(f.openFile); err != nil {
+		// Logging to stderr until the file can be opened, see resume
+		reportError(fmt.Errorf("llog: unable to reopen %s, logging to stderr: %w", f.name, err))
+	}
+}
+
+// openFile opens the log file, which shall be closed, again. The file is
+// nil if it can't be opened. Mutex must be held.
+func (f *logFile) openFile() error {
+	f.reopened = time.Now()
+	file, err := os.OpenFile(f.name, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		f.file = nil
+		return err
+	}
+	f.file = file
+	if f.buf != nil {
+		f.buf.Reset(f.file)
+	}
+	return nil
+}
+
+// resume tries to open the log file again, at most once per
+// failRetryMaxDelay, when it couldn't be reopened, so that logging to the
+// file is resumed. Mutex must be held.
+func (f *logFile) resume() {
+	if time.Since(f.reopened) < failRetryMaxDelay {
+		return
+	}
+	f.openFile()
+}
+
+// lockWrap takes an exclusive lock, shared with other processes, on the
+// lock file belonging to the log file. Call unlock to release the lock.
+func (f *logFile) lockWrap() (unlock func(), err error) {
+	lock, err := os.OpenFile(f.name+".lock", os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(lock); err != nil {
+		lock.Close()
+		return nil, err
+	}
+	return func() {
+		unlockFile(lock)
+		lock.Close()
+	}, nil
+}
+
+// reopenIfMoved reopens the log file if the file currently open is no
+// longer the file with the log file name, i.e. another process has
+// wrapped the log file. Mutex must be held.
+func (f *logFile) reopenIfMoved() bool {
+	current, err := f.file.Stat()
+	if err != nil {
+		return false
+	}
+	named, err := os.Stat(f.name)
+	if err == nil && os.SameFile(current, named) {
+		return false
+	}
+	f.flush()
+	f.file.Close()
+	f.reopen()
+	return true
+}
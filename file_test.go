@@ -0,0 +1,329 @@
+// Unit tests for the log file handling
+package llog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileLockingSharedFile(t *testing.T) {
+	logFileName := "sharedlog.txt"
+	backupFileName := logFileName + ".1"
+	os.Remove(logFileName)
+	os.Remove(backupFileName)
+	SetFileLocking(true)
+	defer SetFileLocking(false)
+
+	// Two log files simulating two processes logging to the same file
+	first, err := openLogFile(logFileName, 2)
+	if err != nil {
+		t.Fatalf("Unable to open log file. Reason: %s", err)
+	}
+	second, err := openLogFile(logFileName, 2)
+	if err != nil {
+		t.Fatalf("Unable to open log file. Reason: %s", err)
+	}
+	for i := 0; i < 60; i++ {
+		fmt.Fprintf(first, "first entry %d which is rather long to reach the limit\n", i)
+		first.entryWritten(LvlInfo)
+	}
+	if !fileExist(backupFileName) {
+		t.Fatalf("Log file was never wrapped")
+	}
+	// The second one shall detect that first one has wrapped the file
+	for i := 0; i < 20; i++ {
+		second.entryWritten(LvlInfo)
+	}
+	fmt.Fprintf(second, "second entry\n")
+	first.Close()
+	second.Close()
+
+	content, _ := os.ReadFile(logFileName)
+	if !strings.Contains(string(content), "second entry") {
+		t.Fatalf("Entry not written to the new log file:\n%s", content)
+	}
+	backup, _ := os.ReadFile(backupFileName)
+	if !strings.Contains(string(backup), "first entry 0") {
+		t.Fatalf("Backup overwritten:\n%s", backup)
+	}
+
+	// Cleanup
+	os.Remove(logFileName)
+	os.Remove(backupFileName)
+	os.Remove(logFileName + ".lock")
+}
+
+func TestWrapCopyTruncate(t *testing.T) {
+	logFileName := "copytruncatelog.txt"
+	backupFileName := logFileName + ".1"
+	os.Remove(logFileName)
+	os.Remove(backupFileName)
+	SetWrapMode(WrapCopyTruncate)
+	defer SetWrapMode(WrapRename)
+
+	file, err := openLogFile(logFileName, 1)
+	if err != nil {
+		t.Fatalf("Unable to open log file. Reason: %s", err)
+	}
+	before, _ := os.Stat(logFileName)
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(file, "entry %d which is rather long to reach the limit fast\n", i)
+		file.entryWritten(LvlInfo)
+	}
+	fmt.Fprintf(file, "after wrap\n")
+	file.Close()
+
+	after, _ := os.Stat(logFileName)
+	if !os.SameFile(before, after) {
+		t.Fatalf("Log file was replaced")
+	}
+	content, _ := os.ReadFile(logFileName)
+	if string(content) != "after wrap\n" {
+		t.Fatalf("Log file was not truncated:\n%s", content)
+	}
+	backup, _ := os.ReadFile(backupFileName)
+	if !strings.Contains(string(backup), "entry 19") {
+		t.Fatalf("Backup not correct:\n%s", backup)
+	}
+
+	// Cleanup
+	os.Remove(logFileName)
+	os.Remove(backupFileName)
+}
+
+func TestWrapFailure(t *testing.T) {
+	logFileName := "wrapfaillog.txt"
+	backupFileName := logFileName + ".1"
+	os.Remove(logFileName)
+	// Make the wrap fail by a backup that can't be removed
+	os.MkdirAll(backupFileName+"/subfolder", os.ModePerm)
+	var reported error
+	SetErrorHandler(func(err error) { reported = err })
+	defer SetErrorHandler(nil)
+
+	file, err := openLogFile(logFileName, 1)
+	if err != nil {
+		t.Fatalf("Unable to open log file. Reason: %s", err)
+	}
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(file, "entry %d which is rather long to reach the limit fast\n", i)
+		file.entryWritten(LvlInfo)
+	}
+	fmt.Fprintf(file, "after wrap\n")
+	file.Close()
+
+	if reported == nil {
+		t.Fatalf("Wrap failure not reported")
+	}
+	content, _ := os.ReadFile(logFileName)
+	if !strings.Contains(string(content), "entry 0") ||
+		!strings.Contains(string(content), "after wrap") {
+		t.Fatalf("Logging shall continue on existing file:\n%s", content)
+	}
+
+	// Cleanup
+	os.Remove(logFileName)
+	os.RemoveAll(backupFileName)
+}
+
+func TestWriteFailureReported(t *testing.T) {
+	logFileName := "writefaillog.txt"
+	var reported []error
+	SetErrorHandler(func(err error) { reported = append(reported, err) })
+	defer SetErrorHandler(nil)
+
+	file, err := openLogFile(logFileName, 100)
+	if err != nil {
+		t.Fatalf("Unable to open log file. Reason: %s", err)
+	}
+	file.file.Close() // Make writes fail
+	fmt.Fprintf(file, "entry 1\n")
+	fmt.Fprintf(file, "entry 2\n")
+	if len(reported) != 1 {
+		t.Fatalf("Expected one reported error, got: %v", reported)
+	}
+	if !strings.Contains(reported[0].Error(), logFileName) {
+		t.Fatalf("File name missing in error: %s", reported[0])
+	}
+
+	// Cleanup
+	file.file = nil
+	os.Remove(logFileName)
+}
+
+func TestFailModes(t *testing.T) {
+	logFileName := "failmodelog.txt"
+	defer SetFailMode(FailStderr)
+	for _, mode := range []FailMode{FailStderr, FailDrop, FailBlock} {
+		SetFailMode(mode)
+		file, err := openLogFile(logFileName, 100)
+		if err != nil {
+			t.Fatalf("Unable to open log file. Reason: %s", err)
+		}
+		healthy := file.file
+		broken, _ := os.Open(logFileName) // Read only, writes will fail
+		file.file = broken
+
+		before := Stats()
+		n, err := fmt.Fprintf(file, "entry while broken\n")
+		after := Stats()
+		if after.WriteErrors <= before.WriteErrors {
+			t.Fatalf("Mode %d: write error not counted", mode)
+		}
+		switch mode {
+		case FailStderr:
+			if err != nil || n == 0 {
+				t.Fatalf("FailStderr shall not fail")
+			}
+		case FailDrop:
+			if err == nil || after.Dropped != before.Dropped+1 {
+				t.Fatalf("FailDrop shall drop and count: %v", after)
+			}
+		}
+
+		// Resume
+		if mode != FailBlock {
+			file.file = healthy
+		} else {
+			healthy.Close() // FailBlock has reopened the file
+		}
+		fmt.Fprintf(file, "entry when resumed\n")
+		file.Close()
+		broken.Close()
+		content, _ := os.ReadFile(logFileName)
+		if !strings.Contains(string(content), "entry when resumed") {
+			t.Fatalf("Mode %d: file logging not resumed:\n%s", mode, content)
+		}
+		if mode == FailBlock && !strings.Contains(string(content), "entry while broken") {
+			t.Fatalf("FailBlock shall write the entry when possible:\n%s", content)
+		}
+		os.Remove(logFileName)
+	}
+}
+
+func TestWrapOnOpen(t *testing.T) {
+	logFileName := "wraponopenlog.txt"
+	backupFileName := logFileName + ".1"
+	os.Remove(backupFileName)
+	os.WriteFile(logFileName, []byte("previous run\n"), 0666)
+	SetWrapOnOpen(true)
+	defer SetWrapOnOpen(false)
+
+	file, err := openLogFile(logFileName, 100)
+	if err != nil {
+		t.Fatalf("Unable to open log file. Reason: %s", err)
+	}
+	fmt.Fprintf(file, "this run\n")
+	file.Close()
+	content, _ := os.ReadFile(logFileName)
+	if string(content) != "this run\n" {
+		t.Fatalf("Log file not wrapped on open:\n%s", content)
+	}
+	backup, _ := os.ReadFile(backupFileName)
+	if string(backup) != "previous run\n" {
+		t.Fatalf("Backup not correct:\n%s", backup)
+	}
+
+	// Empty log file shall not be wrapped
+	os.WriteFile(logFileName, nil, 0666)
+	file, _ = openLogFile(logFileName, 100)
+	file.Close()
+	backup, _ = os.ReadFile(backupFileName)
+	if string(backup) != "previous run\n" {
+		t.Fatalf("Empty log file shall not be wrapped")
+	}
+
+	// Cleanup
+	os.Remove(logFileName)
+	os.Remove(backupFileName)
+}
+
+func TestWrapInterval(t *testing.T) {
+	logFileName := "wrapintervallog.txt"
+	backupFileName := logFileName + ".1"
+	os.Remove(logFileName)
+	os.Remove(backupFileName)
+	clock := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return clock })
+	defer SetClock(nil)
+	SetWrapInterval(time.Hour)
+	defer SetWrapInterval(0)
+
+	file, err := openLogFile(logFileName, 100)
+	if err != nil {
+		t.Fatalf("Unable to open log file. Reason: %s", err)
+	}
+	write := func(text string) {
+		fmt.Fprintf(file, "%s\n", text)
+		file.entryWritten(LvlInfo)
+	}
+	write("first")
+	clock = clock.Add(59 * time.Minute)
+	write("before interval")
+	if _, err := os.Stat(backupFileName); err == nil {
+		t.Fatal("Log file wrapped before interval")
+	}
+	clock = clock.Add(time.Minute)
+	write("at interval")
+	write("after wrap")
+	clock = clock.Add(-time.Hour) // Clock set back
+	write("after clock set back")
+	file.Close()
+
+	backup, _ := os.ReadFile(backupFileName)
+	content, _ := os.ReadFile(logFileName)
+	if string(backup) != "first\nbefore interval\nat interval\n" ||
+		string(content) != "after wrap\nafter clock set back\n" {
+		t.Fatalf("Log file not wrapped on interval:\n%s\n%s", backup, content)
+	}
+
+	// Cleanup
+	os.Remove(logFileName)
+	os.Remove(backupFileName)
+}
+
+func TestResumeAfterReopenFailure(t *testing.T) {
+	logFileName := filepath.Join(t.TempDir(), "resume.log")
+	defer SetFailMode(FailStderr)
+
+	// FailBlock shall retry until the file can be reopened
+	SetFailMode(FailBlock)
+	file, err := openLogFile(logFileName, 100)
+	if err != nil {
+		t.Fatalf("Unable to open log file. Reason: %s", err)
+	}
+	file.file.Close()
+	file.file, _ = os.Open(logFileName) // Read only, writes will fail
+	os.Remove(logFileName)
+	os.Mkdir(logFileName, os.ModePerm) // Reopen will fail
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		os.Remove(logFileName)
+	}()
+	fmt.Fprintf(file, "entry while broken\n")
+	file.Close()
+	if content, _ := os.ReadFile(logFileName); string(content) != "entry while broken\n" {
+		t.Fatalf("FailBlock shall block until written to the file: %q", content)
+	}
+	os.Remove(logFileName)
+
+	// Other modes shall resume file logging when the file can be opened
+	SetFailMode(FailStderr)
+	file, err = openLogFile(logFileName, 100)
+	if err != nil {
+		t.Fatalf("Unable to open log file. Reason: %s", err)
+	}
+	file.file.Close()
+	file.file = nil // As if the file couldn't be reopened
+	file.reopened = time.Now().Add(-failRetryMaxDelay)
+	fmt.Fprintf(file, "entry when resumed\n")
+	file.entryWritten(LvlInfo)
+	file.Close()
+	if content, _ := os.ReadFile(logFileName); string(content) != "entry when resumed\n" {
+		t.Fatalf("File logging not resumed: %q", content)
+	}
+}
@@ -0,0 +1,41 @@
+package llog
+
+import "sync/atomic"
+
+// globFirstError is the first entry on error level or above since start
+// or ResetFirstError, or nil if none
+var globFirstError atomic.Pointer[Entry]
+
+// FirstError returns the first entry on error or panic level written
+// since start or since ResetFirstError, and false if none, for example
+// for a health endpoint reporting since when and why the application is
+// degraded:
+//
+//	if e, ok := llog.FirstError(); ok {
+//		status = fmt.Sprintf("degraded since %s: %s", e.Time.Format(time.RFC3339), e.Message)
+//	}
+func FirstError() (Entry, bool) {
+	if e := globFirstError.Load(); e != nil {
+		return *e, true
+	}
+	return Entry{}, false
+}
+
+// ResetFirstError forgets the entry returned by FirstError, for example
+// when the application has recovered, so that the next entry on error or
+// panic level is remembered.
+func ResetFirstError() {
+	globFirstError.Store(nil)
+}
+
+// isError returns true if entries on level shall be remembered by
+// FirstError.
+func isError(level Level) bool {
+	return level >= LvlError && level != LvlAudit
+}
+
+// rememberError remembers e if it is the first error, see FirstError.
+func rememberError(e Entry) {
+	e.wait = entryWait{} // The context is not kept
+	globFirstError.CompareAndSwap(nil, &e)
+}
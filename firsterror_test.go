@@ -0,0 +1,44 @@
+// Unit tests for firsterror
+package llog
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestFirstError(t *testing.T) {
+	log.SetOutput(new(bytes.Buffer))
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	ResetFirstError()
+	if _, ok := FirstError(); ok {
+		t.Fatalf("No first error expected after reset")
+	}
+
+	Warn("warning")
+	Audit("audit")
+	Error("first %d", 1)
+	_, line, _ := caller(0)
+	WithWorker("w").Error("second")
+	e, ok := FirstError()
+	if !ok || e.Message != "first 1" || e.Level != LvlError || e.Line != line-1 || e.Time.IsZero() {
+		t.Fatalf("Wrong first error: %v %v", e, ok)
+	}
+
+	ResetFirstError()
+	WriteBatch([]Entry{{Level: LvlInfo, Message: "info"}, {Level: LvlPanic, Message: "batch"}})
+	Error("after batch")
+	if e, ok := FirstError(); !ok || e.Message != "batch" || e.Level != LvlPanic {
+		t.Fatalf("Wrong first error: %v %v", e, ok)
+	}
+
+	ResetFirstError()
+	WithContext(context.Background()).Error("with context")
+	if e := globFirstError.Load(); e == nil || e.wait.ctx != nil {
+		t.Fatalf("Context kept by first error: %v", e)
+	}
+	ResetFirstError()
+}
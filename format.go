@@ -0,0 +1,255 @@
+package llog
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// bufferPool are buffers used to format entries
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 256)
+		return &b
+	},
+}
+
+// maxPooledBuffer is the max capacity of buffers put back in the pool,
+// so that a single large entry doesn't keep memory allocated
+const maxPooledBuffer = 64 * 1024
+
+// getBuffer returns an empty buffer from the pool.
+func getBuffer() *[]byte {
+	return bufferPool.Get().(*[]byte)
+}
+
+// putBuffer returns a buffer to the pool.
+func putBuffer(b *[]byte) {
+	if cap(*b) > maxPooledBuffer {
+		return
+	}
+	*b = (*b)[:0]
+	bufferPool.Put(b)
+}
+
+// globOutputMutex serializes writes to outputs that are not log files,
+// as the loggers in the log package do
+var globOutputMutex sync.Mutex
+
+// writeOutput writes a formatted entry to w.
+func writeOutput(w io.Writer, p []byte) {
+	if f, ok := w.(*logFile); ok {
+		f.Write(p) // Errors handled by the fail mode
+		return
+	}
+	globOutputMutex.Lock()
+	defer globOutputMutex.Unlock()
+	w.Write(p)
+}
+
+// callerInfo is the file and line of a program counter
+type callerInfo struct {
+	file string
+	line int
+}
+
+// globCallers caches the file and line of the program counters where
+// entries are written, since runtime.Caller allocates memory. The map is
+// never modified, a new map is stored when a caller is added, so that
+// it can be read without locking.
+var globCallers atomic.Pointer[map[uintptr]callerInfo]
+
+// globCallersMutex serializes additions to globCallers
+var globCallersMutex sync.Mutex
+
+// caller returns the file and line as runtime.Caller(skip), but without
+// allocating memory once the caller is cached.
+func caller(skip int) (file string, line int, ok bool) {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return "", 0, false
+	}
+	pc := pcs[0]
+	if callers := globCallers.Load(); callers != nil {
+		if c, ok := (*callers)[pc]; ok {
+			return c.file, c.line, true
+		}
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	c := callerInfo{file: frame.File, line: frame.Line}
+	globCallersMutex.Lock()
+	defer globCallersMutex.Unlock()
+	callers := make(map[uintptr]callerInfo)
+	if old := globCallers.Load(); old != nil {
+		for oldPC, oldCaller := range *old {
+			callers[oldPC] = oldCaller
+		}
+	}
+	callers[pc] = c
+	globCallers.Store(&callers)
+	return c.file, c.line, true
+}
+
+// appendHeader appends the prefix, date, time and file as the loggers in
+// the log package do with prefix and flags.
+func appendHeader(b []byte, t time.Time, prefix string, flags int, file string, line int) []byte {
+	if flags&log.Lmsgprefix == 0 {
+		b = append(b, prefix...)
+	}
+	if flags&(log.Ldate|log.Ltime|log.Lmicroseconds) != 0 {
+		if flags&log.LUTC != 0 {
+			t = t.UTC()
+		}
+		if flags&log.Ldate != 0 {
+			year, month, day := t.Date()
+			b = appendInt(b, year, 4)
+			b = append(b, '/')
+			b = appendInt(b, int(month), 2)
+			b = append(b, '/')
+			b = appendInt(b, day, 2)
+			b = append(b, ' ')
+		}
+		if flags&(log.Ltime|log.Lmicroseconds) != 0 {
+			hour, min, sec := t.Clock()
+			b = appendInt(b, hour, 2)
+			b = append(b, ':')
+			b = appendInt(b, min, 2)
+			b = append(b, ':')
+			b = appendInt(b, sec, 2)
+			if flags&log.Lmicroseconds != 0 {
+				b = append(b, '.')
+				b = appendInt(b, t.Nanosecond()/1e3, 6)
+			}
+			b = append(b, ' ')
+		}
+	}
+	if flags&(log.Lshortfile|log.Llongfile) != 0 {
+		if flags&log.Lshortfile != 0 {
+			file = shortFile(file)
+		}
+		b = append(b, file...)
+		b = append(b, ':')
+		b = strconv.AppendInt(b, int64(line), 10)
+		b = append(b, ": "...)
+	}
+	if flags&log.Lmsgprefix != 0 {
+		b = append(b, prefix...)
+	}
+	return b
+}
+
+// shortFile returns the final path element of file, as written with
+// the Lshortfile flag.
+func shortFile(file string) string {
+	for i := len(file) - 1; i > 0; i-- {
+		if file[i] == '/' {
+			return file[i+1:]
+		}
+	}
+	return file
+}
+
+// msgKind is how the message of an entry is created from a format
+// string and values
+type msgKind int
+
+const (
+	// msgPrintf formats as fmt.Sprintf(format, v...)
+	msgPrintf msgKind = iota
+	// msgPrintln formats as fmt.Sprintln(v...), without the newline
+	msgPrintln
+	// msgPlain is format as is
+	msgPlain
+)
+
+// appendMessage appends the message created from format and v.
+func appendMessage(b []byte, kind msgKind, format string, v []interface{}) []byte {
+	switch kind {
+	case msgPrintln:
+		b = fmt.Appendln(b, v...)
+		return b[:len(b)-1]
+	case msgPlain:
+		return append(b, format...)
+	}
+	return fmt.Appendf(b, format, v...)
+}
+
+// appendEntryStart appends the entry on the text format, with prefix and
+// flags as in the log package, up to the message.
+func appendEntryStart(b []byte, e *Entry, prefix string, flags int) []byte {
+	return appendEntryLabel(b, e, prefix, flags, e.Level.String())
+}
+
+// appendEntryLabel appends the entry as appendEntryStart, with label as
+// the name of the level. Entries without file, see NoCaller, are written
+// without caller.
+func appendEntryLabel(b []byte, e *Entry, prefix string, flags int, label string) []byte {
+	if e.File == "" {
+		flags &^= log.Lshortfile | log.Llongfile
+	}
+	b = appendHeader(b, e.Time, prefix, flags, e.File, e.Line)
+	if e.Seq != 0 {
+		b = append(b, '#')
+		b = strconv.AppendUint(b, e.Seq, 10)
+		b = append(b, ' ')
+	}
+	if e.Worker != "" {
+		b = append(b, '[')
+		b = append(b, e.Worker...)
+		b = append(b, "] "...)
+	}
+	b = append(b, label...)
+	return append(b, " - "...)
+}
+
+// appendEntryEnd appends the fields and, if missing, the newline ending
+// an entry on the text format.
+func appendEntryEnd(b []byte, fields []Field) []byte {
+	b = appendFields(b, fields)
+	if len(b) == 0 || b[len(b)-1] != '\n' {
+		b = append(b, '\n')
+	}
+	return b
+}
+
+// appendEntry appends the entry on the text format.
+func appendEntry(b []byte, e *Entry, prefix string, flags int) []byte {
+	b = appendEntryStart(b, e, prefix, flags)
+	b = append(b, e.Message...)
+	return appendEntryEnd(b, e.Fields)
+}
+
+// appendInt appends i, zero padded to width digits.
+func appendInt(b []byte, i int, width int) []byte {
+	var digits [20]byte
+	pos := len(digits)
+	for i >= 10 || width > 1 {
+		width--
+		pos--
+		digits[pos] = byte('0' + i%10)
+		i /= 10
+	}
+	pos--
+	digits[pos] = byte('0' + i)
+	return append(b, digits[pos:]...)
+}
+
+// appendFields appends the fields as written after the message.
+func appendFields(b []byte, fields []Field) []byte {
+	if len(fields) == 0 {
+		return b
+	}
+	b = append(b, " |"...)
+	for _, f := range fields {
+		b = append(b, ' ')
+		b = append(b, f.Key...)
+		b = append(b, '=')
+		b = append(b, f.Value...)
+	}
+	return b
+}
@@ -0,0 +1,77 @@
+// Unit tests for formatting of entries
+package llog
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestAppendHeader(t *testing.T) {
+	defer log.SetFlags(log.Flags())
+	defer log.SetPrefix(log.Prefix())
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+
+	// The header shall be as written by the log package, except for time
+	// that might differ
+	allFlags := []int{0, log.Ldate, log.Ltime, log.Lmicroseconds, log.Ldate | log.Ltime | log.LUTC,
+		log.Lshortfile, log.Llongfile, log.LstdFlags | log.Lshortfile | log.Lmsgprefix}
+	for _, flags := range allFlags {
+		for _, prefix := range []string{"", "myapp: "} {
+			log.SetFlags(flags)
+			log.SetPrefix(prefix)
+			buffer.Reset()
+			_, _, line, _ := runtime.Caller(0)
+			Info("message %d", 1)
+			log.Output(1, "INFO - message 1")
+			lines := strings.Split(buffer.String(), "\n")
+			expected := strings.Replace(lines[1], ":"+itoa(line+2)+":", ":"+itoa(line+1)+":", 1)
+			if flags&log.Lmsgprefix == 0 {
+				lines[0], expected = lines[0][len(prefix):], expected[len(prefix):]
+			}
+			if timeLen := headerTimeLen(flags); timeLen > 0 {
+				// Time might differ, only compare the length
+				lines[0], expected = lines[0][timeLen:], expected[timeLen:]
+			}
+			if lines[0] != expected {
+				t.Fatalf("Flags %d prefix %q: got %q, expected %q", flags, prefix, lines[0], expected)
+			}
+		}
+	}
+}
+
+// headerTimeLen returns the length of the date and time in the header.
+func headerTimeLen(flags int) int {
+	n := 0
+	if flags&log.Ldate != 0 {
+		n += len("2009/01/23 ")
+	}
+	if flags&(log.Ltime|log.Lmicroseconds) != 0 {
+		n += len("01:23:23 ")
+	}
+	if flags&log.Lmicroseconds != 0 {
+		n += len(".123456")
+	}
+	return n
+}
+
+func itoa(i int) string {
+	return string(appendInt(nil, i, 1))
+}
+
+func TestAppendInt(t *testing.T) {
+	for _, c := range []struct {
+		i, width int
+		expected string
+	}{{0, 1, "0"}, {7, 2, "07"}, {2019, 4, "2019"}, {123, 6, "000123"}, {12345, 2, "12345"}} {
+		if s := string(appendInt(nil, c.i, c.width)); s != c.expected {
+			t.Fatalf("appendInt(%d, %d) = %q, expected %q", c.i, c.width, s, c.expected)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package llog
+
+// globHeader returns the header of log files or is nil if no header
+var globHeader func() string
+
+// SetHeader sets a function returning a header, for example the
+// application name, version and start time, that is written at the top of
+// the log file set with SetFile after this call. The header is written
+// when the log file is opened and at the top of each new log file after
+// a wrap, so that each log file tells which application produced it.
+// A nil header disables the header.
+func SetHeader(header func() string) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globHeader = header
+}
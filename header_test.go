@@ -0,0 +1,40 @@
+// Unit tests for log file headers
+package llog
+
+import (
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestHeader(t *testing.T) {
+	logFileName := "headerlog.txt"
+	backupFileName := logFileName + ".1"
+	os.Remove(logFileName)
+	os.Remove(backupFileName)
+	SetHeader(func() string { return "myapp version 1.2.3" })
+	defer SetHeader(nil)
+	SetLevel(LvlInfo)
+	if err := SetFile(logFileName, 2); err != nil {
+		t.Fatalf("Unable to log to file. Reason: %s", err)
+	}
+	for i := 0; i < 100; i++ {
+		Info("entry %d", i)
+	}
+	log.SetOutput(os.Stderr)
+	globFile.Close()
+	globFile = nil
+	updateOutput()
+
+	for _, fileName := range []string{logFileName, backupFileName} {
+		content, _ := os.ReadFile(fileName)
+		if !strings.HasPrefix(string(content), "myapp version 1.2.3\n") {
+			t.Fatalf("Header missing in %s:\n%s", fileName, content)
+		}
+	}
+
+	// Cleanup
+	os.Remove(logFileName)
+	os.Remove(backupFileName)
+}
@@ -0,0 +1,130 @@
+package llog
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+)
+
+// jsonSchema is the version of the JSON format, written in the field
+// schema of each entry and metadata record. It is increased when the
+// format is changed in a way that is not backward compatible.
+const jsonSchema = 1
+
+// FileMetadata is the metadata record written on the first line of each
+// log file in the JSON format, see SetJSONMetadata, for example:
+//
+//	{"metadata":{"schema":1,"app":"myapp","version":"v1.2.0","host":"dev1","created":"2009-01-23T01:23:23+01:00"}}
+type FileMetadata struct {
+	// Schema is the version of the JSON format of the file
+	Schema int `json:"schema"`
+	// App is the name of the executable and Version the version of its
+	// main module, if known
+	App     string `json:"app,omitempty"`
+	Version string `json:"version,omitempty"`
+	// Host is the name of the host
+	Host string `json:"host,omitempty"`
+	// Created is the time when the file was created
+	Created time.Time `json:"created"`
+	// Header is the header set with SetHeader or empty if none
+	Header string `json:"header,omitempty"`
+}
+
+// metadataJSON is the JSON encoding of a metadata record
+type metadataJSON struct {
+	Metadata *FileMetadata `json:"metadata"`
+}
+
+// SetJSONMetadata writes a metadata record, see FileMetadata, on the
+// first line of log files in the JSON format set with SetFile after this
+// call, also after a wrap, so that archived log files can be parsed
+// reliably after future format changes. The header set with SetHeader is
+// written in the metadata record of files in the JSON format, whether
+// metadata records are enabled or not. Default is disabled.
+func SetJSONMetadata(enable bool) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globFileOptions.metadata = enable
+}
+
+// newFileMetadata returns the metadata record of a new file.
+func newFileMetadata(header string) FileMetadata {
+	m := FileMetadata{Schema: jsonSchema, App: filepath.Base(os.Args[0]),
+		Created: now(), Header: header}
+	m.Host, _ = os.Hostname()
+	if info, ok := debug.ReadBuildInfo(); ok {
+		m.Version = info.Main.Version
+	}
+	return m
+}
+
+// appendMetadata appends the metadata record followed by a newline.
+func appendMetadata(b []byte, m FileMetadata) []byte {
+	data, _ := json.Marshal(metadataJSON{Metadata: &m}) // Never fails
+	b = append(b, data...)
+	return append(b, '\n')
+}
+
+// parseMetadataLine parses a line with a metadata record. ok is false if
+// the line is not a metadata record.
+func parseMetadataLine(line string) (m FileMetadata, ok bool) {
+	if !strings.HasPrefix(line, `{"metadata":`) {
+		return m, false
+	}
+	var j metadataJSON
+	if err := json.Unmarshal([]byte(line), &j); err != nil || j.Metadata == nil {
+		return m, false
+	}
+	return *j.Metadata, true
+}
+
+// appendJSON appends the entry in the JSON format, followed by a
+// newline, with the time and file written as decided by flags, see
+// FormatJSON.
+func appendJSON(b []byte, e *Entry, flags int) []byte {
+	j := newEntryJSON(e)
+	j.Schema = jsonSchema
+	if flags&(log.Ldate|log.Ltime|log.Lmicroseconds) == 0 {
+		j.Time = nil
+	} else if flags&log.LUTC != 0 {
+		t := e.Time.UTC()
+		j.Time = &t
+	}
+	switch {
+	case flags&log.Lshortfile != 0:
+		j.File = shortFile(j.File)
+	case flags&log.Llongfile == 0:
+		j.File, j.Line = "", 0
+	}
+	data, _ := json.Marshal(j) // Never fails for entryJSON
+	b = append(b, data...)
+	return append(b, '\n')
+}
+
+// parseJSONLine parses a line with an entry in the JSON format. ok is
+// false if the line is not an entry in the JSON format. Fields are
+// returned sorted on key.
+func parseJSONLine(line string) (e Entry, ok bool) {
+	if !strings.HasPrefix(line, "{") {
+		return e, false
+	}
+	var j entryJSON
+	if err := json.Unmarshal([]byte(line), &j); err != nil || j.Level == "" {
+		return e, false
+	}
+	if j.Time != nil {
+		e.Time = *j.Time
+	}
+	e.Seq, e.Worker, e.File, e.Line, e.Message = j.Seq, j.Worker, j.File, j.Line, j.Message
+	e.Level, _ = ParseLevel(j.Level)
+	for key, value := range j.Fields {
+		e.Fields = append(e.Fields, Field{Key: key, Value: value})
+	}
+	sort.Slice(e.Fields, func(a, b int) bool { return e.Fields[a].Key < e.Fields[b].Key })
+	return e, true
+}
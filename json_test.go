@@ -0,0 +1,104 @@
+// Unit tests for json
+package llog
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFormatJSON(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	SetFormat(FormatJSON)
+	defer SetFormat(FormatText)
+
+	WithEvent(evTest).WithWorker("w1").Warn("message with | k=v and \"quotes\"\nsecond line")
+	log.SetFlags(0)
+	Info("no time and file")
+	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"worker":"w1","level":"WARN","file":"json_test.go","line":21,`) ||
+		!strings.Contains(lines[0], `"fields":{"event":"TEST001"}`) || !strings.HasPrefix(lines[0], `{"schema":1,"time":"`) {
+		t.Fatalf("Entry not written as JSON: %s", buffer.String())
+	}
+	if lines[1] != `{"schema":1,"level":"INFO","message":"no time and file"}` {
+		t.Fatalf("Flags not applied: %s", lines[1])
+	}
+	entries := readEntries(t, buffer)
+	if len(entries) != 2 || entries[0].Message != "message with | k=v and \"quotes\"\nsecond line" ||
+		entries[0].Event() != evTest || entries[0].Line != 21 || entries[0].Time.IsZero() ||
+		entries[1].Level != LvlInfo || !entries[1].Time.IsZero() {
+		t.Fatalf("JSON entries not parsed: %v", entries)
+	}
+}
+
+func TestFormatJSONFile(t *testing.T) {
+	logFileName := "jsonlog.txt"
+	os.Remove(logFileName)
+	defer os.Remove(logFileName)
+	SetLevel(LvlInfo)
+	SetFormat(FormatJSON)
+	if err := SetFile(logFileName, 1024); err != nil {
+		t.Fatalf("Unable to log to file. Reason: %s", err)
+	}
+	SetFormat(FormatText)
+	Info("in file")
+	Audit("audit in file")
+	Close()
+
+	file, _ := os.Open(logFileName)
+	defer file.Close()
+	r := NewEntryReader(file)
+	for _, message := range []string{"in file", "audit in file"} {
+		e, err := r.Next()
+		if err != nil || e.Message != message {
+			t.Fatalf("Entry %q not read: %v %v", message, e, err)
+		}
+	}
+}
+
+func TestJSONMetadata(t *testing.T) {
+	logFileName := "jsonmetadatalog.txt"
+	os.Remove(logFileName)
+	os.Remove(logFileName + ".1")
+	defer os.Remove(logFileName)
+	defer os.Remove(logFileName + ".1")
+	SetLevel(LvlInfo)
+	SetFormat(FormatJSON)
+	SetJSONMetadata(true)
+	SetHeader(func() string { return "myapp v1.0" })
+	err := SetFile(logFileName, 1)
+	SetFormat(FormatText)
+	SetJSONMetadata(false)
+	SetHeader(nil)
+	if err != nil {
+		t.Fatalf("Unable to log to file. Reason: %s", err)
+	}
+	for i := 0; i < 20; i++ {
+		Info("entry %d", i)
+	}
+	Close()
+
+	for _, fileName := range []string{logFileName + ".1", logFileName} {
+		file, _ := os.Open(fileName)
+		r := NewEntryReader(file)
+		e, err := r.Next()
+		file.Close()
+		m, ok := r.Metadata()
+		if (err != nil && err != io.EOF) || (err == nil && !strings.HasPrefix(e.Message, "entry")) ||
+			!ok || m.Schema != 1 || m.Header != "myapp v1.0" || m.App == "" || m.Created.IsZero() {
+			t.Fatalf("Metadata not read from %s: %v %v %v", fileName, m, e, err)
+		}
+	}
+	content, _ := os.ReadFile(logFileName)
+	if !strings.HasPrefix(string(content), `{"metadata":{"schema":1,`) {
+		t.Fatalf("Metadata not first in log file: %s", content)
+	}
+}
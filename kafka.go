@@ -0,0 +1,85 @@
+package llog
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// KafkaMessage is a message produced to Kafka.
+type KafkaMessage struct {
+	Key   []byte // nil if no key
+	Value []byte
+}
+
+// KafkaProducer produces messages to a Kafka topic. llog doesn't depend
+// on a Kafka client, instead the application implements the producer
+// using its Kafka client. Produce shall return when the messages are
+// acknowledged by the brokers.
+type KafkaProducer interface {
+	Produce(ctx context.Context, topic string, messages []KafkaMessage) error
+}
+
+// Kafka is a sink, see AddSink, publishing entries to a Kafka topic.
+// Each entry is a message with the entry encoded as JSON, see
+// Entry.MarshalJSON.
+type Kafka struct {
+	// Producer produces the messages
+	Producer KafkaProducer
+	// Topic to publish to
+	Topic string
+	// Key returns the key of the message of an entry. Default is no key.
+	Key func(e Entry) []byte
+	// Timeout of each call to the producer. Default is 10 seconds.
+	Timeout time.Duration
+	// Batch configures the batching of entries. Use Batch.Fallback to
+	// keep the entries in a local file during broker outages.
+	Batch BatchOptions
+
+	once    sync.Once
+	batcher batcher
+}
+
+// Write queues the entry to be published.
+func (k *Kafka) Write(e Entry) error {
+	k.once.Do(k.start)
+	return k.batcher.add(e)
+}
+
+// Close publishes the queued entries.
+func (k *Kafka) Close() error {
+	k.once.Do(func() {})
+	k.batcher.close()
+	return nil
+}
+
+// start fills in defaults and starts the batcher.
+func (k *Kafka) start() {
+	if k.Timeout == 0 {
+		k.Timeout = 10 * time.Second
+	}
+	k.batcher.start(k.Batch, k.send)
+}
+
+// send publishes a batch.
+func (k *Kafka) send(batch []Entry) (int, error) {
+	messages := make([]KafkaMessage, 0, len(batch))
+	for _, e := range batch {
+		value, err := json.Marshal(e)
+		if err != nil {
+			return 0, err
+		}
+		m := KafkaMessage{Value: value}
+		if k.Key != nil {
+			m.Key = k.Key(e)
+		}
+		messages = append(messages, m)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), k.Timeout)
+	defer cancel()
+	if err := k.Producer.Produce(ctx, k.Topic, messages); err != nil {
+		return 0, err
+	}
+	return len(batch), nil
+}
@@ -0,0 +1,55 @@
+// Unit tests for the Kafka sink
+package llog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"testing"
+)
+
+// testKafkaProducer is a producer keeping the messages produced
+type testKafkaProducer struct {
+	mutex    sync.Mutex
+	topic    string
+	messages []KafkaMessage
+}
+
+func (p *testKafkaProducer) Produce(ctx context.Context, topic string, messages []KafkaMessage) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.topic = topic
+	p.messages = append(p.messages, messages...)
+	return nil
+}
+
+func TestKafka(t *testing.T) {
+	log.SetOutput(new(bytes.Buffer))
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	producer := &testKafkaProducer{}
+	sink := &Kafka{
+		Producer: producer,
+		Topic:    "logs",
+		Key:      func(e Entry) []byte { return []byte(e.Worker) },
+	}
+	AddSink(sink)
+	WithWorker("conn-42").Warn("disconnected")
+	RemoveSink(sink)
+
+	producer.mutex.Lock()
+	defer producer.mutex.Unlock()
+	if producer.topic != "logs" || len(producer.messages) != 1 || string(producer.messages[0].Key) != "conn-42" {
+		t.Fatalf("Message not produced correctly: %v", producer.messages)
+	}
+	var e entryJSON
+	if err := json.Unmarshal(producer.messages[0].Value, &e); err != nil {
+		t.Fatalf("Message is not JSON. Reason: %s", err)
+	}
+	if e.Level != "WARN" || e.Message != "disconnected" || e.Worker != "conn-42" || e.Line == 0 {
+		t.Fatalf("Message not correct: %s", producer.messages[0].Value)
+	}
+}
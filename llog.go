@@ -1,7 +1,8 @@
 // Package llog (Level Logger) extends the standard log package with:
 //
-//    * configurable log levels
-//    * log file wrapping if configurable size exceeded
+//   - configurable log levels
+//   - log file wrapping if configurable size exceeded
+//   - a separate audit stream that is never suppressed
 //
 // llog is using the "standard" logger in the log package.
 //
@@ -9,10 +10,13 @@
 package llog
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log"
-	"os"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Level type is used for different debuggnig levels
@@ -31,19 +35,16 @@ const (
 	LvlError Level = 5
 	// LvlPanic a non-recoverable error has occurred
 	LvlPanic Level = 6
+	// LvlAudit audit events - always logged regardless of level set
+	LvlAudit Level = 7
 )
 
-// globLevelSet is the current level set. Default is LvlInfo.
-var globLevelSet = LvlInfo
-
-// globFileName is the name of file where logging output goes or nil if stderr
-var globFileName string
+// globLevelSet is the current level set. Default is LvlInfo. Atomic,
+// since it is read by each call to the log functions.
+var globLevelSet atomic.Int32
 
 // globFile is the file where logging output goes or nil if stderr
-var globFile *os.File
-
-// globMaxSizeKB max size of log file until wrap
-var globMaxSizeKB int
+var globFile *logFile
 
 // globMutex is a mutex to secure thread safety
 var globMutex = &sync.Mutex{}
@@ -53,100 +54,332 @@ func init() {
 	// Log on format:
 	// 2009/01/23 01:23:23 file.go:23: INFO - message
 	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
+	globLevelSet.Store(int32(LvlInfo))
+	updateOutput()
 }
 
 // SetLevel sets lowest log priority that shall be written to the output.
 func SetLevel(level Level) {
-	globLevelSet = level
+	globLevelSet.Store(int32(level))
+}
+
+// globOutputLevel is the lowest level written to the log output, see
+// SetOutputLevel
+var globOutputLevel Level
+
+// SetOutputLevel sets the lowest level written to the log output, i.e.
+// the log file or stderr, when it shall be higher than the level set with
+// SetLevel, which is the lowest level written anywhere. Together with
+// SetSinkLevel each destination gets its own level, for example:
+//
+//	llog.SetLevel(llog.LvlDebug)          // Lowest level of all destinations
+//	llog.SetOutputLevel(llog.LvlInfo)     // Info and above to the log file
+//	llog.SetSinkLevel(sink, llog.LvlWarn) // Warnings and above to the sink
+//
+// Audit entries are always written. Level 0 writes all entries (default).
+func SetOutputLevel(level Level) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globOutputLevel = level
+	updateOutput()
+}
+
+// levelSet returns the level set with SetLevel.
+func levelSet() Level {
+	return Level(globLevelSet.Load())
+}
+
+// outputConfig is a snapshot of the configuration used when writing
+// entries, so that entries are written without locking globMutex.
+type outputConfig struct {
+	file           *logFile
+	auditFile      *logFile
+	auditLogger    *log.Logger
+	handlers       []*entryHandler
+	sequence       bool
+	messageIDs     bool
+	traceExtractor func(ctx context.Context) (traceID, spanID string)
+	eventLevels    map[EventCode]Level
+	stopped        bool
+	dualStream     bool
+	format         Format
+	clock          func() time.Time
+	stackOnError   bool
+	maxLength      int
+	sanitize       bool
+	outputLevel    Level
+	hooks          []*entryHook
+	formatter      Formatter
+	pretty         *prettyFormat
+}
+
+// globOutput is the current output configuration
+var globOutput atomic.Pointer[outputConfig]
+
+// updateOutput updates the output configuration. It shall be called,
+// with globMutex held, each time any of the configuration in
+// outputConfig is changed.
+func updateOutput() {
+	globOutput.Store(&outputConfig{
+		file:           globFile,
+		auditFile:      globAuditFile,
+		auditLogger:    globAuditLogger,
+		handlers:       globHandlers,
+		sequence:       globSequenceEnabled,
+		messageIDs:     globMessageIDs,
+		traceExtractor: globTraceExtractor,
+		eventLevels:    globEventLevels,
+		stopped:        globStopped,
+		dualStream:     globDualStream,
+		format:         globFileOptions.format,
+		clock:          globClock,
+		stackOnError:   globStackOnFirstError,
+		maxLength:      globMaxMessageLength,
+		sanitize:       globSanitize,
+		outputLevel:    globOutputLevel,
+		hooks:          globHooks,
+		formatter:      globFormatter,
+		pretty:         globPretty,
+	})
 }
 
 // SetFile logs to a file instead of stderr (default). If the file is more
 // than maxSizeKB the old file will be backed up and a new log file
 // will be written. If an error occurs stderr logging will be kept.
 func SetFile(fileName string, maxSizeKB int) error {
-	var err error
-	globFileName = fileName
-	globFile, err = os.OpenFile(globFileName, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	file, err := openLogFile(fileName, maxSizeKB)
 	if err != nil {
-		globFile = nil
 		return err
 	}
-	// Don't close the file with intention
+	setLogFile(file)
+	return nil
+}
 
+// setLogFile makes file the log file.
+func setLogFile(file *logFile) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	if file.format == FormatBinary {
+		file.startBinary()
+	}
+	if globHeader != nil || file.metadata {
+		file.setHeader(globHeader)
+	}
+	if globFile != nil {
+		globFile.Close()
+	}
+	globFile = file
 	log.SetOutput(globFile)
-	globMaxSizeKB = maxSizeKB
-	return nil
+	updateOutput()
 }
 
-// globCounter is counting to know when log wrap should be checked
-var globCounter int
+// syncLog flushes and syncs the log file, if any.
+func syncLog() {
+	globMutex.Lock()
+	file := globFile
+	globMutex.Unlock()
 
-//wrapLogIfNeeded wraps the log if globMaxSizeKB has exceeded. To avoid
-//file accesses for every log entry the actual file check is only done
-//every 20th log write.
-func wrapLogIfNeeded() {
-	globMutex.Lock() // For thread safety
-	defer globMutex.Unlock()
+	if file != nil {
+		file.Sync()
+	}
+}
+
+// levelNames are the names of the levels as written in the log
+var levelNames = map[Level]string{
+	LvlTrace: "TRACE",
+	LvlDebug: "DEBUG",
+	LvlInfo:  "INFO",
+	LvlWarn:  "WARN",
+	LvlError: "ERROR",
+	LvlPanic: "PANIC",
+	LvlAudit: "AUDIT",
+}
 
-	if globFile == nil {
-		// Not storing to a file
+// String returns the name of the level as written in the log.
+func (level Level) String() string {
+	if name, ok := levelNames[level]; ok {
+		return name
+	}
+	return fmt.Sprintf("LEVEL%d", int(level))
+}
+
+// output writes an entry to the log. Calldepth is the count of the
+// number of frames to skip when computing the file name and line
+// number, as in log.Output. The message is created from format and v as
+// decided by kind.
+//
+// The entry is formatted into a pooled buffer, with the prefix and flags
+// of the standard logger, and written directly to its output. No lock is
+// held until the entry is written, so that concurrent calls are only
+// serialized by the write.
+func (l *Logger) output(calldepth int, level Level, kind msgKind, format string, v []interface{}) {
+	config := globOutput.Load()
+	if config.stopped {
 		return
 	}
+	calldepth += l.callerSkip
+	now := config.clock()
+	logFile, auditFile, auditLogger := config.file, config.auditFile, config.auditLogger
+	handlers := config.handlers
+	toOutput := level >= config.outputLevel || level == LvlAudit
+	if !toOutput && len(handlers) == 0 {
+		return
+	}
+
+	toAuditFile := level == LvlAudit && auditFile != nil
+	var writer io.Writer
+	var prefix string
+	var flags int
+	if toAuditFile {
+		writer, prefix, flags = auditFile, auditLogger.Prefix(), auditLogger.Flags()
+	} else if config.dualStream && logFile == nil {
+		writer, prefix, flags = consoleWriter(level), log.Prefix(), log.Flags()
+	} else {
+		writer, prefix, flags = log.Writer(), log.Prefix(), log.Flags()
+	}
+	flags = l.callerFlags(flags)
+	binaryFile := !toAuditFile && logFile != nil && writer == logFile && logFile.binary != nil
+	outFormat := config.format
+	if logFile != nil && writer == logFile {
+		outFormat = logFile.format
+	}
+	if toAuditFile {
+		outFormat = FormatText
+	}
+	jsonOutput := outFormat == FormatJSON
+	// The entry is formatted after the message unless written as is
+	rebuild := jsonOutput || outFormat == FormatPretty || len(config.hooks) > 0 ||
+		(config.formatter != nil && !toAuditFile)
 
-	globCounter++
-	if globCounter >= 20 {
-		globCounter = 0 // Reset counter
-		globFile.Sync()
-		info, _ := globFile.Stat()
-		if (info.Size() / 1024) >= int64(globMaxSizeKB) {
-			// Time to wrap
-			log.SetOutput(os.Stderr) // Temporary log to stderr
-			globFile.Close() // Close file
-			backupFileName := globFileName + ".1"
-			os.Remove(backupFileName)           // Remove backup if existing
-			os.Rename(globFileName, backupFileName) // Make backup
-			SetFile(globFileName, globMaxSizeKB)    // Start over on log
+	entry := Entry{Time: now, Worker: l.worker, Level: level, File: "???"}
+	// Only entries written to the output are numbered, so that the
+	// numbers have no gaps
+	if config.sequence && toOutput && !rebuild {
+		entry.Seq = nextSeq(toAuditFile)
+	}
+	entry.Fields = l.fields(config.traceExtractor)
+	entry.wait = entryWait{ctx: l.ctx, maxWait: l.maxWait}
+	if config.messageIDs && kind == msgPrintf {
+		entry.Fields = append(entry.Fields, messageFields(format, v)...)
+	}
+	if config.sanitize && !jsonOutput {
+		entry.Worker = sanitizeString(entry.Worker)
+		entry.Fields = sanitizeFields(entry.Fields)
+	}
+	if l.noCaller {
+		entry.File = ""
+	} else if flags&(log.Lshortfile|log.Llongfile) != 0 || len(handlers) > 0 || binaryFile {
+		if file, line, ok := caller(calldepth); ok {
+			entry.File, entry.Line = file, line
 		}
 	}
-}
 
-func loglevel(level Level, prefix string, format string, v ...interface{}) {
-	if level >= globLevelSet {
-		wrapLogIfNeeded()
-		log.Output(3, fmt.Sprintf(prefix+format, v...))
+	buf := getBuffer()
+	defer putBuffer(buf)
+	b := *buf
+	if !rebuild {
+		b = appendEntryStart(b, &entry, prefix, flags)
+	}
+	msgStart := len(b)
+	b = appendMessage(b, kind, format, v)
+	if config.sanitize && !jsonOutput {
+		b = sanitizeMessage(b, msgStart)
+	}
+	if config.stackOnError && level >= LvlError && level != LvlAudit &&
+		firstError(l.errorKey(kind, format, b[msgStart:])) {
+		b = appendStack(b, calldepth)
+	}
+	b = truncateMessage(b, msgStart, config.maxLength)
+	msgEnd := len(b)
+
+	var e *Entry
+	if len(handlers) > 0 || binaryFile || rebuild {
+		e = new(Entry)
+		*e = entry
+		e.Message = string(b[msgStart:msgEnd])
+	}
+	if rebuild {
+		if !runHooks(config.hooks, e) {
+			return
+		}
+		if config.sequence && toOutput {
+			// Numbered after the filters, so that dropped entries leave no gaps
+			e.Seq = nextSeq(toAuditFile)
+		}
+		if toAuditFile {
+			b = appendEntry(b[:0], e, prefix, flags)
+		} else {
+			b = formatEntry(b[:0], e, writer, prefix, flags, outFormat, config)
+		}
+	} else {
+		b = appendEntryEnd(b, entry.Fields)
+	}
+	*buf = b
+	if isError(level) && globFirstError.Load() == nil {
+		if e != nil {
+			rememberError(*e)
+		} else {
+			first := entry
+			first.Message = string(b[msgStart:msgEnd])
+			rememberError(first)
+		}
 	}
-}
 
-// Trace writes a log on trace level
-func Trace(format string, v ...interface{}) {
-	loglevel(LvlTrace, "TRACE - ", format, v...)
+	switch {
+	case !toOutput:
+		// Only written to the handlers, see SetOutputLevel
+	case toAuditFile:
+		writeOutput(writer, b)
+		auditFile.entryWritten(level)
+	default:
+		if binaryFile {
+			logFile.writeEntry(e, flags)
+		} else {
+			writeOutput(writer, b)
+		}
+		if logFile != nil {
+			logFile.entryWritten(level)
+		}
+	}
+
+	for _, h := range handlers {
+		h.handle(e)
+	}
 }
 
-// Debug writes a log on debug level
-func Debug(format string, v ...interface{}) {
-	loglevel(LvlDebug, "DEBUG - ", format, v...)
+func (l *Logger) loglevel(level Level, kind msgKind, format string, v []interface{}) {
+	level = l.eventLevel(level)
+	if level >= levelSet() {
+		l.output(3, level, kind, format, v)
+	}
 }
 
 // Info writes a log on info level
 func Info(format string, v ...interface{}) {
-	loglevel(LvlInfo, "INFO - ", format, v...)
+	globLogger.loglevel(LvlInfo, msgPrintf, format, v)
 }
 
 // Warn writes a log on warn level
 func Warn(format string, v ...interface{}) {
-	loglevel(LvlWarn, "WARN - ", format, v...)
+	globLogger.loglevel(LvlWarn, msgPrintf, format, v)
 }
 
 // Error writes a log on error level
 func Error(format string, v ...interface{}) {
-	loglevel(LvlError, "ERROR - ", format, v...)
+	globLogger.loglevel(LvlError, msgPrintf, format, v)
 }
 
 // Panic writes a log on panic level, flush
-// the log and calls panic()
+// the log and calls panic(). Panic panics even if the panic level is not
+// written.
 func Panic(format string, v ...interface{}) {
-	if LvlPanic >= globLevelSet {
-		log.Output(2, fmt.Sprintf("PANIC - "+format, v...))
-		panic(fmt.Sprintf(format, v...))
-	}
+	globLogger.panic(msgPrintf, format, v)
+}
+
+// PanicErr writes a log on panic level with msg and err, flush the log
+// and calls panic() with an error wrapping err, so that the recovered
+// value can be inspected with errors.Is and errors.As. PanicErr panics
+// even if the panic level is not written.
+func PanicErr(err error, msg string) {
+	globLogger.panicErr(err, msg)
 }
@@ -3,8 +3,10 @@ package llog
 
 import (
 	"bytes"
+	"io"
 	"log"
 	"os"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -42,7 +44,16 @@ func logAllLevelsExceptPanic() {
 	Error("this is an error - param %d and %s", 1, "param 2")
 }
 
+// skipInRelease skips tests of trace and debug entries, which are not
+// written when built with the llog_release build tag.
+func skipInRelease(t *testing.T) {
+	if Release {
+		t.Skip("Trace and debug are removed in release builds")
+	}
+}
+
 func TestLevelTrace(t *testing.T) {
+	skipInRelease(t)
 	SetLevel(LvlTrace)
 	l, result := logAndGetLevelsLogged()
 	if !l.trace || !l.debug || !l.info || !l.warn || !l.err {
@@ -54,6 +65,7 @@ func TestLevelTrace(t *testing.T) {
 	}
 }
 func TestLevelDebug(t *testing.T) {
+	skipInRelease(t)
 	SetLevel(LvlDebug)
 	l, result := logAndGetLevelsLogged()
 	if l.trace || !l.debug || !l.info || !l.warn || !l.err {
@@ -262,3 +274,80 @@ func TestLogWrapInFolder(t *testing.T) {
 	globFile = nil
 	os.RemoveAll("afolder")
 }
+
+func BenchmarkInfo(b *testing.B) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Info("connection established")
+	}
+}
+
+func BenchmarkInfoArgs(b *testing.B) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Info("connection %s established after %d retries", "conn-42", 3)
+	}
+}
+
+func BenchmarkFiltered(b *testing.B) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Trace("connection %s established after %d retries", "conn-42", 3)
+	}
+}
+
+func BenchmarkFile(b *testing.B) {
+	logFileName := "benchlog.txt"
+	os.Remove(logFileName)
+	os.Remove(logFileName + ".1")
+	SetBuffering(64*1024, time.Second)
+	defer SetBuffering(0, 0)
+	if err := SetFile(logFileName, 1024); err != nil {
+		b.Fatalf("Unable to log to file. Reason: %s", err)
+	}
+	SetLevel(LvlInfo)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Info("connection %s established after %d retries", "conn-42", 3)
+	}
+	b.StopTimer()
+	Close()
+	os.Remove(logFileName)
+	os.Remove(logFileName + ".1")
+}
+
+func BenchmarkParallel(b *testing.B) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlTrace)
+	defer SetLevel(LvlInfo)
+	b.ReportAllocs()
+	b.SetParallelism(64 / runtime.GOMAXPROCS(0))
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			Trace("connection %s established after %d retries", "conn-42", 3)
+		}
+	})
+}
+
+func BenchmarkParallelFiltered(b *testing.B) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	b.ReportAllocs()
+	b.SetParallelism(64 / runtime.GOMAXPROCS(0))
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			Trace("connection %s established after %d retries", "conn-42", 3)
+		}
+	})
+}
@@ -0,0 +1,221 @@
+package llog
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// globMessageIDs is true if message IDs are written, see SetMessageIDs
+var globMessageIDs bool
+
+// SetMessageIDs writes the message ID of each entry, the format string
+// given to the log functions, and its formatted arguments as the fields
+// msg_id and msg_args, so that a Locale can translate the entry without
+// parsing the message. The ID and each argument are escaped as URL path
+// segments and the arguments are separated by commas:
+//
+//	2009/01/23 01:23:23 file.go:23: WARN - Disk sda is 95% full | msg_id=Disk%20%25s%20is%20%25d%25%25%20full msg_args=sda,95
+//
+// Entries written with a message only, such as by Must or Check, have no
+// message ID. Default is disabled.
+func SetMessageIDs(enable bool) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globMessageIDs = enable
+	updateOutput()
+}
+
+// Locale presents entries in another language, for example in a user
+// interface, while the log files keep the canonical messages. The
+// entries are localized when formatted, not when written:
+//
+//	llog.SetMessageIDs(true)
+//	sv := &llog.Locale{
+//		Levels:   map[llog.Level]string{llog.LvlWarn: "VARNING"},
+//		Messages: map[string]string{"Disk %s is %d%% full": "Disken %s är %d%% full"},
+//	}
+//	e, _ := reader.Next()     // ... WARN - Disk sda is 95% full
+//	fmt.Println(sv.Format(e)) // ... VARNING - Disken sda är 95% full
+//
+// A Locale shall not be modified after it is used. It is safe for
+// concurrent use.
+type Locale struct {
+	// Levels are the localized level labels. Levels not in the map keep
+	// their names.
+	Levels map[Level]string
+	// Messages is the message catalog, mapping message IDs to translated
+	// templates. The ID of a message is the format string given to the
+	// log functions, for example "Disk %s is %d%% full", and is written
+	// with the entry if enabled with SetMessageIDs. The arguments written
+	// with the entry are inserted in the template, in order or as given
+	// by explicit argument indexes, such as %[2]s. Messages not in the
+	// catalog, or without message ID, are kept as written.
+	Messages map[string]string
+
+	once      sync.Once
+	templates map[string][]localeSegment
+}
+
+// localeSegment is a literal text or, if arg >= 0, the verb text for
+// the argument with the index arg in a format string
+type localeSegment struct {
+	text string
+	arg  int
+}
+
+// verbPattern matches the verbs, and escaped percent signs, of format
+// strings
+var verbPattern = regexp.MustCompile(`%%|%[-+# 0]*(\[\d+\])?(\d+|\*)?(\.(\d+|\*)?)?(\[\d+\])?[a-zA-Z]`)
+
+// LevelName returns the localized label of level.
+func (loc *Locale) LevelName(level Level) string {
+	if name, ok := loc.Levels[level]; ok {
+		return name
+	}
+	return level.String()
+}
+
+// Localize returns the entry with the message translated by the message
+// catalog. The msg_id and msg_args fields are removed.
+func (loc *Locale) Localize(e Entry) Entry {
+	id, args, fields, ok := messageID(e.Fields)
+	if !ok {
+		return e
+	}
+	e.Fields = fields
+	loc.once.Do(loc.compile)
+	template, ok := loc.templates[id]
+	if !ok {
+		return e
+	}
+	var b strings.Builder
+	for _, s := range template {
+		if s.arg < 0 {
+			b.WriteString(s.text)
+		} else if s.arg < len(args) {
+			b.WriteString(args[s.arg])
+		}
+	}
+	e.Message = b.String()
+	return e
+}
+
+// Format returns the entry on the default log format, as Entry.String,
+// with the localized level label and message.
+func (loc *Locale) Format(e Entry) string {
+	e = loc.Localize(e)
+	b := appendEntryLabel(nil, &e, "", log.Ldate|log.Ltime|log.Lshortfile, loc.LevelName(e.Level))
+	b = append(b, e.Message...)
+	b = appendEntryEnd(b, e.Fields)
+	return string(b[:len(b)-1])
+}
+
+// compile parses the templates of the message catalog.
+func (loc *Locale) compile() {
+	loc.templates = make(map[string][]localeSegment, len(loc.Messages))
+	for id, template := range loc.Messages {
+		loc.templates[id] = parseFormat(template)
+	}
+}
+
+// messageFields returns the msg_id and msg_args fields of a message
+// written with format and v. Each argument is formatted by the first verb
+// using it.
+func messageFields(format string, v []interface{}) []Field {
+	fields := []Field{{Key: "msg_id", Value: url.PathEscape(format)}}
+	if len(v) == 0 {
+		return fields
+	}
+	args := make([]string, len(v))
+	formatted := make([]bool, len(v))
+	for _, s := range parseFormat(format) {
+		if s.arg >= 0 && s.arg < len(v) && !formatted[s.arg] {
+			args[s.arg] = fmt.Sprintf(s.text, v[s.arg])
+			formatted[s.arg] = true
+		}
+	}
+	for i := range args {
+		if !formatted[i] {
+			args[i] = fmt.Sprint(v[i])
+		}
+		args[i] = url.PathEscape(args[i])
+	}
+	return append(fields, Field{Key: "msg_args", Value: strings.Join(args, ",")})
+}
+
+// messageID returns the message ID and arguments written in the fields
+// by SetMessageIDs and the other fields. ok is false if there is no
+// valid message ID.
+func messageID(fields []Field) (id string, args []string, other []Field, ok bool) {
+	var rawArgs string
+	hasArgs := false
+	for _, f := range fields {
+		switch f.Key {
+		case "msg_id":
+			var err error
+			if id, err = url.PathUnescape(f.Value); err != nil {
+				return "", nil, nil, false
+			}
+			ok = true
+		case "msg_args":
+			rawArgs, hasArgs = f.Value, true
+		default:
+			other = append(other, f)
+		}
+	}
+	if !ok {
+		return "", nil, nil, false
+	}
+	if hasArgs {
+		for _, arg := range strings.Split(rawArgs, ",") {
+			arg, err := url.PathUnescape(arg)
+			if err != nil {
+				return "", nil, nil, false
+			}
+			args = append(args, arg)
+		}
+	}
+	return id, args, other, true
+}
+
+// parseFormat splits a format string into literal texts and the verbs,
+// without explicit argument indexes, with the argument indexes numbered
+// as by the fmt package.
+func parseFormat(format string) []localeSegment {
+	var segments []localeSegment
+	next, pos := 0, 0
+	for _, m := range verbPattern.FindAllStringSubmatchIndex(format, -1) {
+		if m[0] > pos {
+			segments = append(segments, localeSegment{text: format[pos:m[0]], arg: -1})
+		}
+		pos = m[1]
+		verb := format[m[0]:m[1]]
+		if verb == "%%" {
+			segments = append(segments, localeSegment{text: "%", arg: -1})
+			continue
+		}
+		for _, group := range []int{2, 10} { // Explicit argument indexes
+			if m[group] >= 0 {
+				if n, err := strconv.Atoi(format[m[group]+1 : m[group+1]-1]); err == nil && n > 0 {
+					next = n - 1
+				}
+			}
+		}
+		for _, group := range []int{10, 2} { // Removed from the end
+			if m[group] >= 0 {
+				verb = verb[:m[group]-m[0]] + verb[m[group+1]-m[0]:]
+			}
+		}
+		segments = append(segments, localeSegment{text: verb, arg: next})
+		next++
+	}
+	if pos < len(format) {
+		segments = append(segments, localeSegment{text: format[pos:], arg: -1})
+	}
+	return segments
+}
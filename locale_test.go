@@ -0,0 +1,95 @@
+// Unit tests for locale
+package llog
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocale(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	SetMessageIDs(true)
+	defer SetMessageIDs(false)
+
+	sv := &Locale{
+		Levels: map[Level]string{LvlWarn: "VARNING", LvlError: "FEL"},
+		Messages: map[string]string{
+			"Disk %s is %d%% full":   "Disken %s är %d%% full",
+			"Copied %d files to %q":  "Kopierade till %[2]q: %[1]d filer",
+			"Connection lost":        "Anslutningen förlorades",
+			"Connection lost to %v":  "Anslutningen till %v förlorades",
+			"Temperature %.1f is %s": "Temperaturen %.1f är %s",
+			"%s %s":                  "%[2]s / %[1]s",
+		},
+	}
+	Warn("Disk %s is %d%% full", "sda", 95)
+	Info("Copied %d files to %q", 12, "/tmp")
+	Info("Connection lost")
+	Info("Connection lost to %v", "db:5432")
+	Info("Temperature %.1f is %s", 41.46, "too high")
+	Info("%s %s", "a b", "c,d")
+	Info("Not in the catalog %d", 1)
+	want := []string{
+		"Disken sda är 95% full",
+		"Kopierade till \"/tmp\": 12 filer",
+		"Anslutningen förlorades",
+		"Anslutningen till db:5432 förlorades",
+		"Temperaturen 41.5 är too high",
+		"c,d / a b",
+		"Not in the catalog 1",
+	}
+	entries := readEntries(t, buffer)
+	if len(entries) != len(want) {
+		t.Fatalf("Got %d entries, want %d", len(entries), len(want))
+	}
+	for i, e := range entries {
+		if got := sv.Localize(e); got.Message != want[i] || len(got.Fields) != 0 {
+			t.Errorf("Localize(%q) = %q %v, want %q", e.Message, got.Message, got.Fields, want[i])
+		}
+	}
+	if got := sv.Localize(Entry{Message: "Connection lost"}).Message; got != "Connection lost" {
+		t.Fatalf("Entry without message ID translated: %q", got)
+	}
+
+	if sv.LevelName(LvlWarn) != "VARNING" || sv.LevelName(LvlInfo) != "INFO" {
+		t.Fatalf("Wrong level names")
+	}
+	e := Entry{Time: time.Date(2001, 2, 3, 4, 5, 6, 0, time.Local), Level: LvlWarn, File: "/src/disk.go",
+		Line: 12, Worker: "w1", Message: "Disk sda is 95% full", Fields: []Field{{Key: "event", Value: "DSK001"}}}
+	e.Fields = append(e.Fields, messageFields("Disk %s is %d%% full", []interface{}{"sda", 95})...)
+	wantFormat := "2001/02/03 04:05:06 disk.go:12: [w1] VARNING - Disken sda är 95% full | event=DSK001"
+	if got := sv.Format(e); got != wantFormat {
+		t.Fatalf("Got %q, want %q", got, wantFormat)
+	}
+	if e.Message != "Disk sda is 95% full" || len(e.Fields) != 3 || (&Locale{}).Format(e) != "2001/02/03 04:05:06 disk.go:12: [w1] WARN - Disk sda is 95% full | event=DSK001" {
+		t.Fatalf("Entry modified or wrong format without translations")
+	}
+}
+
+func TestMessageIDs(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+
+	Info("Disk %s is %d%% full", "sda", 95)
+	SetMessageIDs(true)
+	Info("Disk %s is %d%% full", "sda", 95)
+	Check(os.ErrClosed, "closed")
+	SetMessageIDs(false)
+
+	lines := strings.Split(buffer.String(), "\n")
+	if strings.Contains(lines[0], "msg_id") || strings.Contains(lines[2], "msg_id") {
+		t.Fatalf("Message ID written when not enabled or without format: %s", buffer)
+	}
+	if !strings.HasSuffix(lines[1], "INFO - Disk sda is 95% full | msg_id=Disk%20%25s%20is%20%25d%25%25%20full msg_args=sda,95") {
+		t.Fatalf("Message ID not written: %s", lines[1])
+	}
+}
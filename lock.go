@@ -0,0 +1,15 @@
+package llog
+
+// SetFileLocking makes it safe for several processes to log to the same
+// file. The wrap of a log file, opened after this call, is protected by
+// an advisory lock on a lock file with the name of the log file and the
+// suffix ".lock". A process that finds out that another process has
+// wrapped the log file starts logging to the new log file.
+//
+// File locking is supported on Windows and most Unix systems. On other
+// systems only the detection of wraps made by other processes is done.
+func SetFileLocking(enable bool) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globFileOptions.locking = enable
+}
@@ -0,0 +1,15 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+
+package llog
+
+import "os"
+
+// lockFile does nothing since file locking is not supported.
+func lockFile(file *os.File) error {
+	return nil
+}
+
+// unlockFile does nothing since file locking is not supported.
+func unlockFile(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,108 @@
+package llog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Logger writes entries tagged with a worker, for example a connection
+// or a request, so that interleaved entries from concurrent workers can
+// be told apart. The worker tag is written before the level:
+//
+//	2009/01/23 01:23:23 file.go:23: [conn-42] INFO - message
+//
+// A Logger uses the same output and level as the package functions and
+// is safe for concurrent use.
+type Logger struct {
+	worker string
+	ctx    context.Context // context of entries or nil, see WithContext
+	event  EventCode       // event code of entries, see WithEvent
+	// requestID is the request ID of entries, see ForRequest
+	requestID string
+	// noCaller and callerSkip change the caller of entries, see WithCaller
+	noCaller   bool
+	callerSkip int
+	// maxWait is the max wait of entries for room in the queue of sinks,
+	// or 0 for the MaxWait of the sink, see WithMaxWait
+	maxWait time.Duration
+}
+
+// globLogger is the logger used by the package functions
+var globLogger = &Logger{}
+
+// WithWorker returns a logger writing entries tagged with worker.
+func WithWorker(worker string) *Logger {
+	return globLogger.WithWorker(worker)
+}
+
+// WithWorker returns a copy of the logger writing entries tagged with
+// worker.
+func (l *Logger) WithWorker(worker string) *Logger {
+	c := *l
+	c.worker = worker
+	return &c
+}
+
+// Info writes a log on info level
+func (l *Logger) Info(format string, v ...interface{}) {
+	l.loglevel(LvlInfo, msgPrintf, format, v)
+}
+
+// Warn writes a log on warn level
+func (l *Logger) Warn(format string, v ...interface{}) {
+	l.loglevel(LvlWarn, msgPrintf, format, v)
+}
+
+// Error writes a log on error level
+func (l *Logger) Error(format string, v ...interface{}) {
+	l.loglevel(LvlError, msgPrintf, format, v)
+}
+
+// Panic writes a log on panic level, flush
+// the log and calls panic(). Panic panics even if the panic level is not
+// written.
+func (l *Logger) Panic(format string, v ...interface{}) {
+	l.panic(msgPrintf, format, v)
+}
+
+// PanicErr writes a log on panic level with msg and err, flush the log
+// and calls panic() with an error wrapping err, so that the recovered
+// value can be inspected with errors.Is and errors.As. PanicErr panics
+// even if the panic level is not written.
+func (l *Logger) PanicErr(err error, msg string) {
+	l.panicErr(err, msg)
+}
+
+// Audit writes a log on audit level. Audit entries are always written,
+// regardless of the level set with SetLevel.
+func (l *Logger) Audit(format string, v ...interface{}) {
+	l.output(2, LvlAudit, msgPrintf, format, v)
+}
+
+// panic is called by Panic with the same call depth as loglevel.
+func (l *Logger) panic(kind msgKind, format string, v []interface{}) {
+	msg := string(appendMessage(nil, kind, format, v))
+	if LvlPanic >= levelSet() {
+		l.output(3, LvlPanic, kind, format, v)
+	}
+	syncLog()
+	panicked(msg)
+	panic(msg)
+}
+
+// panicErr is called by PanicErr with the same call depth as loglevel.
+func (l *Logger) panicErr(err error, msg string) {
+	if err == nil {
+		err = errors.New(msg)
+	} else {
+		err = fmt.Errorf("%s: %w", msg, err)
+	}
+	if LvlPanic >= levelSet() {
+		l.output(3, LvlPanic, msgPlain, err.Error(), nil)
+	}
+	syncLog()
+	panicked(err.Error())
+	panic(err)
+}
@@ -0,0 +1,88 @@
+// Unit tests for Logger
+package llog
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWithWorker(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+
+	conn := WithWorker("conn-42")
+	conn.Info("connected")
+	conn.Debug("not written")
+	conn.WithWorker("conn-43").Warn("other")
+	Info("untagged")
+	func() {
+		defer func() { recover() }()
+		conn.Panic("crashed")
+	}()
+
+	output := buffer.String()
+	if !strings.Contains(output, "logger_test.go:20: [conn-42] INFO - connected") {
+		t.Fatalf("Worker tag or file not written: %s", output)
+	}
+	if !strings.Contains(output, "logger_test.go:26: [conn-42] PANIC - crashed") {
+		t.Fatalf("Panic not written with worker tag: %s", output)
+	}
+	entries := readEntries(t, buffer)
+	if len(entries) != 4 || entries[1].Worker != "conn-43" || entries[2].Worker != "" {
+		t.Fatalf("Entries not correct: %v", entries)
+	}
+}
+
+func TestPanicErr(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+
+	recovered := func(f func()) (r interface{}) {
+		defer func() { r = recover() }()
+		f()
+		return nil
+	}
+	cause := &os.PathError{Op: "open", Path: "config.json", Err: os.ErrNotExist}
+	r := recovered(func() { PanicErr(cause, "unable to load config") })
+	err, ok := r.(error)
+	var pathErr *os.PathError
+	if !ok || !errors.As(err, &pathErr) || !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Error not preserved in panic: %#v", r)
+	}
+	r = recovered(func() { WithWorker("w1").PanicErr(nil, "no error") })
+	if err, ok := r.(error); !ok || err.Error() != "no error" {
+		t.Fatalf("Wrong panic value without error: %#v", r)
+	}
+	output := buffer.String()
+	if !strings.Contains(output, "logger_test.go:54: PANIC - unable to load config: open config.json: file does not exist\n") ||
+		!strings.Contains(output, "logger_test.go:60: [w1] PANIC - no error\n") {
+		t.Fatalf("Panic not logged correctly: %s", output)
+	}
+}
+
+func TestPanicAboveLevel(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlAudit)
+	defer SetLevel(LvlInfo)
+
+	if r := recoverPanic(func() { Panic("panic %d", 1) }); r != "panic 1" {
+		t.Fatalf("Panic did not panic: %#v", r)
+	}
+	r := recoverPanic(func() { WithWorker("w1").PanicErr(os.ErrClosed, "closed") })
+	if err, ok := r.(error); !ok || !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("PanicErr did not panic: %#v", r)
+	}
+	if buffer.Len() != 0 {
+		t.Fatalf("Panic level written above level: %s", buffer)
+	}
+}
@@ -0,0 +1,86 @@
+package llog
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Loki is a sink, see AddSink, pushing entries to Grafana Loki using the
+// HTTP push API. Each entry gets the static labels and the label level,
+// with the level in lower case.
+type Loki struct {
+	// URL of the push endpoint, for example
+	// http://localhost:3100/loki/api/v1/push
+	URL string
+	// Labels are static labels added to all entries, for example
+	// {"app": "myapp"}
+	Labels map[string]string
+	// Headers are added to each request, for example X-Scope-OrgID or
+	// authorization
+	Headers map[string]string
+	// Client used to push. Default is a client with 10 seconds timeout.
+	Client *http.Client
+	// Batch configures the batching of entries
+	Batch BatchOptions
+
+	once    sync.Once
+	batcher batcher
+}
+
+// Write queues the entry to be pushed.
+func (l *Loki) Write(e Entry) error {
+	l.once.Do(l.start)
+	return l.batcher.add(e)
+}
+
+// Close pushes the queued entries.
+func (l *Loki) Close() error {
+	l.once.Do(func() {})
+	l.batcher.close()
+	return nil
+}
+
+// start fills in defaults and starts the batcher.
+func (l *Loki) start() {
+	if l.Client == nil {
+		l.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	l.batcher.start(l.Batch, l.send)
+}
+
+// lokiStream is a stream in a push request
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// send pushes a batch, with one stream per level.
+func (l *Loki) send(batch []Entry) (int, error) {
+	var streams []*lokiStream
+	byLevel := make(map[Level]*lokiStream)
+	for _, e := range batch {
+		stream, ok := byLevel[e.Level]
+		if !ok {
+			labels := map[string]string{"level": strings.ToLower(e.Level.String())}
+			for key, value := range l.Labels {
+				labels[key] = value
+			}
+			stream = &lokiStream{Stream: labels}
+			byLevel[e.Level] = stream
+			streams = append(streams, stream)
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(e.Time.UnixNano(), 10), e.String()})
+	}
+	body, err := json.Marshal(map[string]interface{}{"streams": streams})
+	if err != nil {
+		return 0, err
+	}
+	if err := postJSON(l.Client, l.URL, l.Headers, body); err != nil {
+		return 0, err
+	}
+	return len(batch), nil
+}
@@ -0,0 +1,54 @@
+// Unit tests for the Loki sink
+package llog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLoki(t *testing.T) {
+	var mutex sync.Mutex
+	var streams []lokiStream
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ Streams []lokiStream }
+		json.NewDecoder(r.Body).Decode(&req)
+		mutex.Lock()
+		defer mutex.Unlock()
+		streams = append(streams, req.Streams...)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	log.SetOutput(new(bytes.Buffer))
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	sink := &Loki{URL: server.URL, Labels: map[string]string{"app": "myapp"}}
+	AddSink(sink)
+	Info("first")
+	Error("failed")
+	Info("second")
+	RemoveSink(sink)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(streams) != 2 {
+		t.Fatalf("Expected one stream per level, got %v", streams)
+	}
+	info := streams[0]
+	if info.Stream["level"] != "info" || info.Stream["app"] != "myapp" || len(info.Values) != 2 {
+		t.Fatalf("Info stream not correct: %v", info)
+	}
+	if !strings.HasSuffix(info.Values[1][1], "INFO - second") {
+		t.Fatalf("Wrong line: %s", info.Values[1][1])
+	}
+	if streams[1].Stream["level"] != "error" || len(streams[1].Values) != 1 {
+		t.Fatalf("Error stream not correct: %v", streams[1])
+	}
+}
@@ -0,0 +1,85 @@
+package llog
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// MQTTClient publishes messages to an MQTT broker. llog doesn't depend
+// on an MQTT client, instead the application implements the client using
+// its MQTT client, for example by waiting for the token returned by
+// Publish in Eclipse Paho.
+type MQTTClient interface {
+	Publish(topic string, qos byte, retained bool, payload []byte) error
+}
+
+// MQTT is a sink, see AddSink, publishing entries of a minimum level to
+// an MQTT topic, while the full log is kept in the log file. Each entry
+// is a message with the entry encoded as JSON, see Entry.MarshalJSON.
+// Audit entries are never published, as by Webhook.
+type MQTT struct {
+	// Client publishes the messages
+	Client MQTTClient
+	// Topic is a template of the topic to publish to, where {device} is
+	// replaced with Device and {level} with the level in lower case, for
+	// example "devices/{device}/log/{level}"
+	Topic string
+	// Device is the ID of the device
+	Device string
+	// QoS is the MQTT quality of service (0, 1 or 2)
+	QoS byte
+	// MinLevel is the lowest level published, below LvlAudit. Default is
+	// LvlWarn.
+	MinLevel Level
+	// Batch configures the queueing of entries
+	Batch BatchOptions
+
+	once    sync.Once
+	batcher batcher
+}
+
+// Write queues the entry to be published, if it is of the minimum level
+// and not an audit entry.
+func (m *MQTT) Write(e Entry) error {
+	m.once.Do(m.start)
+	if e.Level < m.MinLevel || e.Level == LvlAudit {
+		return nil
+	}
+	return m.batcher.add(e)
+}
+
+// Close publishes the queued entries.
+func (m *MQTT) Close() error {
+	m.once.Do(func() {})
+	m.batcher.close()
+	return nil
+}
+
+// start fills in defaults and starts the batcher.
+func (m *MQTT) start() {
+	if m.MinLevel == 0 {
+		m.MinLevel = LvlWarn
+	}
+	m.batcher.start(m.Batch, m.send)
+}
+
+// topic returns the topic of an entry.
+func (m *MQTT) topic(e Entry) string {
+	return strings.NewReplacer("{device}", m.Device,
+		"{level}", strings.ToLower(e.Level.String())).Replace(m.Topic)
+}
+
+// send publishes the entries in the batch, one message per entry.
+func (m *MQTT) send(batch []Entry) (int, error) {
+	for i, e := range batch {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return i, err
+		}
+		if err := m.Client.Publish(m.topic(e), m.QoS, false, payload); err != nil {
+			return i, err
+		}
+	}
+	return len(batch), nil
+}
@@ -0,0 +1,63 @@
+// Unit tests for the MQTT sink
+package llog
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testMQTTClient is a client keeping the messages published
+type testMQTTClient struct {
+	mutex    sync.Mutex
+	fail     int // number of publishes that shall fail
+	topics   []string
+	payloads []string
+}
+
+func (c *testMQTTClient) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if qos != 1 {
+		return errors.New("wrong QoS")
+	}
+	if c.fail > 0 && len(c.topics) == 1 {
+		c.fail--
+		return errors.New("not connected")
+	}
+	c.topics = append(c.topics, topic)
+	c.payloads = append(c.payloads, string(payload))
+	return nil
+}
+
+func TestMQTT(t *testing.T) {
+	log.SetOutput(new(bytes.Buffer))
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	batchRetryDelay = time.Millisecond
+	defer func() { batchRetryDelay = 500 * time.Millisecond }()
+
+	client := &testMQTTClient{fail: 1}
+	sink := &MQTT{Client: client, Topic: "devices/{device}/log/{level}", Device: "dev1", QoS: 1}
+	AddSink(sink)
+	Info("not published")
+	Warn("low battery")
+	Error("sensor failed")
+	Audit("not published")
+	RemoveSink(sink)
+
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	if len(client.topics) != 2 || client.topics[0] != "devices/dev1/log/warn" ||
+		client.topics[1] != "devices/dev1/log/error" {
+		t.Fatalf("Wrong topics: %v", client.topics)
+	}
+	if !strings.Contains(client.payloads[1], `"message":"sensor failed"`) {
+		t.Fatalf("Wrong payload: %s", client.payloads[1])
+	}
+}
@@ -0,0 +1,170 @@
+package llog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// OTLP is a sink, see AddSink, sending entries as log records to an
+// OpenTelemetry collector using OTLP over HTTP with JSON encoding. The
+// levels are mapped to OpenTelemetry severity numbers and the trace_id
+// and span_id fields, see SetTraceExtractor, to the trace context of the
+// log records. Other fields are sent as attributes.
+type OTLP struct {
+	// URL of the logs endpoint, for example http://localhost:4318/v1/logs
+	URL string
+	// ServiceName is the service.name resource attribute. Default is the
+	// name of the executable.
+	ServiceName string
+	// Headers are added to each request, for example for authorization
+	Headers map[string]string
+	// Client used to send. Default is a client with 10 seconds timeout.
+	Client *http.Client
+	// Batch configures the batching of entries
+	Batch BatchOptions
+
+	once    sync.Once
+	batcher batcher
+}
+
+// otlpSeverity are the OpenTelemetry severity numbers of the levels
+var otlpSeverity = map[Level]int{
+	LvlTrace: 1,
+	LvlDebug: 5,
+	LvlInfo:  9,
+	LvlWarn:  13,
+	LvlError: 17,
+	LvlPanic: 21,
+	LvlAudit: 10,
+}
+
+// Write queues the entry to be sent.
+func (o *OTLP) Write(e Entry) error {
+	o.once.Do(o.start)
+	return o.batcher.add(e)
+}
+
+// Close sends the queued entries.
+func (o *OTLP) Close() error {
+	o.once.Do(func() {})
+	o.batcher.close()
+	return nil
+}
+
+// start fills in defaults and starts the batcher.
+func (o *OTLP) start() {
+	if o.ServiceName == "" {
+		o.ServiceName = filepath.Base(os.Args[0])
+	}
+	if o.Client == nil {
+		o.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	o.batcher.start(o.Batch, o.send)
+}
+
+// otlpValue is an OTLP AnyValue
+type otlpValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    string  `json:"intValue,omitempty"`
+}
+
+// otlpAttribute is an OTLP KeyValue
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+// otlpRecord is an OTLP LogRecord
+type otlpRecord struct {
+	TimeUnixNano   string          `json:"timeUnixNano"`
+	SeverityNumber int             `json:"severityNumber"`
+	SeverityText   string          `json:"severityText"`
+	Body           otlpValue       `json:"body"`
+	Attributes     []otlpAttribute `json:"attributes,omitempty"`
+	TraceID        string          `json:"traceId,omitempty"`
+	SpanID         string          `json:"spanId,omitempty"`
+}
+
+// stringAttribute returns an attribute with a string value.
+func stringAttribute(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{StringValue: &value}}
+}
+
+// send sends a batch to the collector.
+func (o *OTLP) send(batch []Entry) (int, error) {
+	records := make([]otlpRecord, 0, len(batch))
+	for _, e := range batch {
+		message := e.Message
+		r := otlpRecord{
+			TimeUnixNano:   strconv.FormatInt(e.Time.UnixNano(), 10),
+			SeverityNumber: otlpSeverity[e.Level],
+			SeverityText:   e.Level.String(),
+			Body:           otlpValue{StringValue: &message},
+			Attributes: []otlpAttribute{
+				stringAttribute("code.filepath", e.File),
+				{Key: "code.lineno", Value: otlpValue{IntValue: strconv.Itoa(e.Line)}},
+			},
+		}
+		if e.Worker != "" {
+			r.Attributes = append(r.Attributes, stringAttribute("worker", e.Worker))
+		}
+		for _, f := range e.Fields {
+			switch f.Key {
+			case "trace_id":
+				r.TraceID = f.Value
+			case "span_id":
+				r.SpanID = f.Value
+			default:
+				r.Attributes = append(r.Attributes, stringAttribute(f.Key, f.Value))
+			}
+		}
+		records = append(records, r)
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"resourceLogs": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": []otlpAttribute{stringAttribute("service.name", o.ServiceName)},
+			},
+			"scopeLogs": []interface{}{map[string]interface{}{
+				"scope":      map[string]string{"name": "llog"},
+				"logRecords": records,
+			}},
+		}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := postJSON(o.Client, o.URL, o.Headers, body); err != nil {
+		return 0, err
+	}
+	return len(batch), nil
+}
+
+// postJSON posts a JSON body. An error is returned if the post fails or
+// the response isn't successful.
+func postJSON(client *http.Client, url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return nil
+}
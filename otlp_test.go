@@ -0,0 +1,120 @@
+// Unit tests for the OTLP sink
+package llog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// otlpRequest is the part of an OTLP request checked in the tests
+type otlpRequest struct {
+	ResourceLogs []struct {
+		Resource struct {
+			Attributes []otlpAttribute
+		}
+		ScopeLogs []struct {
+			LogRecords []otlpRecord
+		}
+	}
+}
+
+func TestOTLP(t *testing.T) {
+	var mutex sync.Mutex
+	var records []otlpRecord
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req otlpRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		mutex.Lock()
+		defer mutex.Unlock()
+		auth = r.Header.Get("Authorization")
+		for _, rl := range req.ResourceLogs {
+			if *rl.Resource.Attributes[0].Value.StringValue != "myservice" {
+				t.Errorf("Wrong resource: %v", rl.Resource)
+			}
+			for _, sl := range rl.ScopeLogs {
+				records = append(records, sl.LogRecords...)
+			}
+		}
+	}))
+	defer server.Close()
+
+	log.SetOutput(new(bytes.Buffer))
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	SetTraceExtractor(func(ctx context.Context) (string, string) {
+		return "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	})
+	defer SetTraceExtractor(nil)
+
+	sink := &OTLP{
+		URL:         server.URL,
+		ServiceName: "myservice",
+		Headers:     map[string]string{"Authorization": "Bearer secret"},
+		Batch:       BatchOptions{Size: 2},
+	}
+	AddSink(sink)
+	Info("first")
+	WithWorker("w1").WithContext(context.Background()).Error("second")
+	Warn("third") // Sent when closed
+	RemoveSink(sink)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(records) != 3 || auth != "Bearer secret" {
+		t.Fatalf("Expected 3 records with authorization, got %d: %v", len(records), records)
+	}
+	r := records[1]
+	if *r.Body.StringValue != "second" || r.SeverityNumber != 17 || r.SeverityText != "ERROR" {
+		t.Fatalf("Record not correct: %+v", r)
+	}
+	if !strings.HasSuffix(*r.Attributes[0].Value.StringValue, "otlp_test.go") ||
+		*r.Attributes[2].Value.StringValue != "w1" || r.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("Attributes not correct: %+v", r.Attributes)
+	}
+}
+
+func TestOTLPFallback(t *testing.T) {
+	fallbackFileName := "otlpfallback.txt"
+	os.Remove(fallbackFileName)
+	defer os.Remove(fallbackFileName)
+	batchRetryDelay = time.Millisecond
+	defer func() { batchRetryDelay = 500 * time.Millisecond }()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		io.Copy(io.Discard, r.Body)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	log.SetOutput(new(bytes.Buffer))
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	sink := &OTLP{URL: server.URL, Batch: BatchOptions{Fallback: fallbackFileName}}
+	AddSink(sink)
+	Info("lost connection")
+	RemoveSink(sink)
+
+	if calls.Load() != 4 {
+		t.Fatalf("Expected 1 attempt and 3 retries, got %d", calls.Load())
+	}
+	content, _ := os.ReadFile(fallbackFileName)
+	if !strings.Contains(string(content), "INFO - lost connection") {
+		t.Fatalf("Entry not written to fallback file: %s", content)
+	}
+	if err := sink.Write(Entry{}); err != errSinkClosed {
+		t.Fatalf("Write to closed sink shall fail, got %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package llog
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// entryRegexp matches the first line of an entry
+var entryRegexp = regexp.MustCompile(`^(?:(\d{4}/\d{2}/\d{2}) )?(?:(\d{2}:\d{2}:\d{2}(?:\.\d{6})?) )?(?:(\S+):(\d+): )?(?:#(\d+) )?(?:\[([^\]]*)\] )?(TRACE|DEBUG|INFO|WARN|ERROR|PANIC|AUDIT) - (.*)$`)
+
+// chainRegexp matches the hash chain field of entries in hash chained
+// audit files
+var chainRegexp = regexp.MustCompile(` chain=[0-9a-f]{64}$`)
+
+// fieldsRegexp matches the fields after the message
+var fieldsRegexp = regexp.MustCompile(` \| (\w+=\S*(?: \w+=\S*)*)$`)
+
+// EntryReader reads entries from a log file written by llog.
+//
+// Entries are expected to be on the default llog format:
+//
+//	2009/01/23 01:23:23 file.go:23: INFO - message
+//
+// The date, time, file name, sequence number and worker tag parts are
+// optional, which means that log files written with other flags set in
+// the log package can be read as long as no log prefix is used. Entries
+// in the JSON format, see FormatJSON, are also read. Lines not
+// starting with an entry belong to the message of the previous entry
+// (multi line messages). Lines before the first entry, such as a header,
+// are ignored. Fields after the message are returned in Entry.Fields.
+type EntryReader struct {
+	scanner  *bufio.Scanner
+	next     *Entry // next entry, which might continue on more lines
+	nextJSON bool   // true if next is in the JSON format
+	metadata *FileMetadata
+	location *time.Location // location of times in the text format
+}
+
+// NewEntryReader returns a reader reading entries from r.
+func NewEntryReader(r io.Reader) *EntryReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	return &EntryReader{scanner: scanner, location: time.Local}
+}
+
+// SetLocation sets the location of the times of entries in the text
+// format, for example time.UTC for log files written with the LUTC flag
+// set in the log package. Default is time.Local. Times in the JSON format
+// have their offset written.
+func (r *EntryReader) SetLocation(loc *time.Location) {
+	r.location = loc
+}
+
+// Next returns the next entry. io.EOF is returned when there are no more
+// entries.
+func (r *EntryReader) Next() (Entry, error) {
+	for r.scanner.Scan() {
+		line := chainRegexp.ReplaceAllString(r.scanner.Text(), "")
+		if m, ok := parseMetadataLine(line); ok {
+			r.metadata = &m
+			continue
+		}
+		e, isJSON := parseJSONLine(line)
+		ok := isJSON
+		if !ok {
+			e, ok = parseLine(line, r.location)
+		}
+		if !ok {
+			if r.next != nil {
+				r.next.Message += "\n" + line
+			}
+			continue
+		}
+		if r.next != nil {
+			result := r.pending()
+			r.next, r.nextJSON = &e, isJSON
+			return result, nil
+		}
+		r.next, r.nextJSON = &e, isJSON
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Entry{}, err
+	}
+	if r.next != nil {
+		result := r.pending()
+		r.next = nil
+		return result, nil
+	}
+	return Entry{}, io.EOF
+}
+
+// Metadata returns the latest metadata record read, see SetJSONMetadata.
+// ok is false if no metadata record has been read.
+func (r *EntryReader) Metadata() (m FileMetadata, ok bool) {
+	if r.metadata == nil {
+		return m, false
+	}
+	return *r.metadata, true
+}
+
+// pending returns the next entry, with the fields parsed if the entry is
+// in the text format.
+func (r *EntryReader) pending() Entry {
+	if r.nextJSON {
+		return *r.next
+	}
+	return parseFields(*r.next)
+}
+
+// parseFields moves the fields at the end of the message of the entry to
+// the fields of the entry.
+func parseFields(e Entry) Entry {
+	m := fieldsRegexp.FindStringSubmatchIndex(e.Message)
+	if m == nil {
+		return e
+	}
+	for _, field := range strings.Split(e.Message[m[2]:m[3]], " ") {
+		key, value, _ := strings.Cut(field, "=")
+		e.Fields = append(e.Fields, Field{Key: key, Value: value})
+	}
+	e.Message = e.Message[:m[0]]
+	return e
+}
+
+// parseLine parses the first line of an entry, with the time in loc. ok
+// is false if the line is not the first line of an entry.
+func parseLine(line string, loc *time.Location) (e Entry, ok bool) {
+	m := entryRegexp.FindStringSubmatch(line)
+	if m == nil {
+		return e, false
+	}
+	date, clock := m[1], m[2]
+	if date != "" || clock != "" {
+		if date == "" {
+			date = "0000/01/01"
+		}
+		if clock == "" {
+			clock = "00:00:00"
+		}
+		e.Time, _ = time.ParseInLocation("2006/01/02 15:04:05", date+" "+clock, loc)
+	}
+	e.File = m[3]
+	e.Line, _ = strconv.Atoi(m[4])
+	e.Seq, _ = strconv.ParseUint(m[5], 10, 64)
+	e.Worker = m[6]
+	e.Level, _ = ParseLevel(m[7])
+	e.Message = strings.TrimSuffix(m[8], "\r")
+	return e, true
+}
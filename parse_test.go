@@ -0,0 +1,65 @@
+// Unit tests for reading entries
+package llog
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testLog = `myapp version 1.2.3
+2019/01/26 22:57:15 example.go:18: INFO - This is an info entry. Parameter 23
+2019/01/26 22:57:16 example.go:19: WARN - A multi line
+warning | trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7
+2019/01/26 22:57:17.123456 /src/example.go:20: AUDIT - user joe logged in chain=0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef
+#7 ERROR - no date, time or file
+`
+
+func TestEntryReader(t *testing.T) {
+	reader := NewEntryReader(strings.NewReader(testLog))
+	var entries []Entry
+	for {
+		e, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Unable to read. Reason: %s", err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("Expected 4 entries, got %d: %v", len(entries), entries)
+	}
+	e := entries[0]
+	expectedTime := time.Date(2019, 1, 26, 22, 57, 15, 0, time.Local)
+	if !e.Time.Equal(expectedTime) || e.Level != LvlInfo || e.File != "example.go" ||
+		e.Line != 18 || e.Message != "This is an info entry. Parameter 23" {
+		t.Fatalf("First entry not correct: %#v", e)
+	}
+	if entries[1].Message != "A multi line\nwarning" {
+		t.Fatalf("Multi line entry not correct: %q", entries[1].Message)
+	}
+	if len(entries[1].Fields) != 2 || entries[1].Fields[1] != (Field{Key: "span_id", Value: "00f067aa0ba902b7"}) {
+		t.Fatalf("Fields not correct: %v", entries[1].Fields)
+	}
+	e = entries[2]
+	if e.Time.Nanosecond() != 123456000 || e.File != "/src/example.go" ||
+		e.Message != "user joe logged in" {
+		t.Fatalf("Audit entry not correct: %#v", e)
+	}
+	e = entries[3]
+	if !e.Time.IsZero() || e.File != "" || e.Level != LvlError || e.Seq != 7 {
+		t.Fatalf("Last entry not correct: %#v", e)
+	}
+}
+
+func TestEntryReaderLocation(t *testing.T) {
+	reader := NewEntryReader(strings.NewReader("2019/01/26 22:57:15 a.go:1: INFO - utc\n"))
+	reader.SetLocation(time.UTC)
+	e, err := reader.Next()
+	if err != nil || !e.Time.Equal(time.Date(2019, 1, 26, 22, 57, 15, 0, time.UTC)) {
+		t.Fatalf("Time not read in location: %v %v", e.Time, err)
+	}
+}
@@ -0,0 +1,106 @@
+package llog
+
+import "io"
+
+// Formatter appends an entry, formatted and followed by a newline, to b
+// and returns the extended buffer, see SetFormatter.
+type Formatter func(b []byte, e *Entry) []byte
+
+// entryHook is a hook or filter, see AddHook and AddFilter
+type entryHook struct {
+	fn func(e *Entry) bool // false if the entry shall be dropped
+}
+
+// globHooks are the hooks and filters, in the order added. A new slice
+// is created when hooks are added or removed.
+var globHooks []*entryHook
+
+// globFormatter is the formatter set with SetFormatter or nil
+var globFormatter Formatter
+
+// SetFormatter sets a formatter writing the entries to the log output,
+// i.e. the log file or stderr, instead of the text or JSON format. The
+// formatter is called with the complete entry, after the hooks, and
+// shall append the formatted entry, followed by a newline, to b:
+//
+//	llog.SetFormatter(func(b []byte, e *llog.Entry) []byte {
+//		return fmt.Appendf(b, "%s [%s] %s\n", e.Time.Format(time.RFC3339), e.Level, e.Message)
+//	})
+//
+// Log files in the binary format and the audit file are not affected. A
+// nil formatter restores the format set with SetFormat.
+func SetFormatter(formatter Formatter) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globFormatter = formatter
+	updateOutput()
+}
+
+// AddHook makes hook be called with each entry before it is written to
+// the log output, the handlers and the sinks. The hook may modify the
+// entry, for example add fields. Hooks and filters are called in the
+// order they are added, from the goroutine writing the entry. Call
+// remove to remove the hook.
+func AddHook(hook func(e *Entry)) (remove func()) {
+	return addHook(&entryHook{fn: func(e *Entry) bool {
+		hook(e)
+		return true
+	}})
+}
+
+// AddFilter makes filter be called with each entry before it is written
+// to the log output, the handlers and the sinks. Entries for which the
+// filter returns false are dropped, for example entries from a noisy
+// library. Audit entries are never dropped. Call remove to remove the
+// filter.
+func AddFilter(filter func(e *Entry) bool) (remove func()) {
+	return addHook(&entryHook{fn: filter})
+}
+
+// addHook adds a hook or filter and returns a function removing it.
+func addHook(h *entryHook) (remove func()) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	hooks := make([]*entryHook, 0, len(globHooks)+1)
+	globHooks = append(append(hooks, globHooks...), h)
+	updateOutput()
+	return func() {
+		globMutex.Lock()
+		defer globMutex.Unlock()
+		hooks := make([]*entryHook, 0, len(globHooks))
+		for _, hook := range globHooks {
+			if hook != h {
+				hooks = append(hooks, hook)
+			}
+		}
+		globHooks = hooks
+		updateOutput()
+	}
+}
+
+// runHooks calls the hooks and filters with the entry. It returns false
+// if the entry shall be dropped.
+func runHooks(hooks []*entryHook, e *Entry) bool {
+	for _, h := range hooks {
+		if !h.fn(e) && e.Level != LvlAudit {
+			return false
+		}
+	}
+	return true
+}
+
+// formatEntry appends the entry, followed by a newline, to be written
+// to w: with the formatter, if any, otherwise in format, with prefix and
+// flags for the text format.
+func formatEntry(b []byte, e *Entry, w io.Writer, prefix string, flags int, format Format, config *outputConfig) []byte {
+	switch {
+	case config.formatter != nil:
+		return config.formatter(b, e)
+	case format == FormatJSON:
+		return appendJSON(b, e, flags)
+	case format == FormatPretty && config.pretty != nil:
+		return config.pretty.appendPretty(b, e, config.pretty.colored(w))
+	default:
+		return appendEntry(b, e, prefix, flags)
+	}
+}
@@ -0,0 +1,94 @@
+// Unit tests for pipeline
+package llog
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestHooksAndFilters(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	sink := &testSink{}
+	AddSink(sink)
+	defer RemoveSink(sink)
+
+	removeHook := AddHook(func(e *Entry) {
+		e.Fields = append(e.Fields, Field{Key: "host", Value: "h1"})
+	})
+	removeFilter := AddFilter(func(e *Entry) bool {
+		return !strings.HasPrefix(e.Message, "noisy")
+	})
+	Info("kept")
+	Info("noisy library")
+	Audit("noisy audit") // Audit entries are never dropped
+	WriteBatch([]Entry{{Level: LvlWarn, Message: "noisy batch"}, {Level: LvlWarn, Message: "batch"}})
+	removeHook()
+	removeFilter()
+	Info("noisy after")
+
+	entries := readEntries(t, buffer)
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Message+fmt.Sprint(e.Fields))
+	}
+	want := []string{"kept[{host h1}]", "noisy audit[{host h1}]", "batch[{host h1}]", "noisy after[]"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("Got %q, want %q", got, want)
+	}
+	if messages := sink.messages(); fmt.Sprint(messages) != "[kept noisy audit batch noisy after]" {
+		t.Fatalf("Wrong entries to sink: %q", messages)
+	}
+}
+
+func TestFormatter(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	SetFormatter(func(b []byte, e *Entry) []byte {
+		return fmt.Appendf(b, "%s|%s|%d|%s\n", e.Level, shortFile(e.File), e.Line, e.Message)
+	})
+	defer SetFormatter(nil)
+
+	Warn("hello %d", 1)
+	_, line, _ := caller(0)
+	WriteBatch([]Entry{{Level: LvlError, File: "x.go", Line: 2, Message: "batch"}})
+
+	want := fmt.Sprintf("WARN|pipeline_test.go|%d|hello 1\nERROR|x.go|2|batch\n", line-1)
+	if buffer.String() != want {
+		t.Fatalf("Got %q, want %q", buffer.String(), want)
+	}
+
+	SetFormatter(nil)
+	buffer.Reset()
+	Info("text")
+	if !strings.HasSuffix(buffer.String(), "INFO - text\n") {
+		t.Fatalf("Format not restored: %q", buffer.String())
+	}
+}
+
+func TestFilterSequenceNumbers(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	SetSequenceNumbers(true)
+	defer SetSequenceNumbers(false)
+	remove := AddFilter(func(e *Entry) bool { return e.Message != "dropped" })
+	defer remove()
+
+	Info("a")
+	Info("dropped")
+	Info("b")
+	entries := readEntries(t, buffer)
+	if len(entries) != 2 || entries[1].Seq != entries[0].Seq+1 {
+		t.Fatalf("Dropped entry numbered: %v", entries)
+	}
+}
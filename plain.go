@@ -0,0 +1,114 @@
+package llog
+
+// The ln variants format the message as fmt.Sprintln, i.e. with spaces
+// between the values, and the Msg variants write the message as is,
+// without formatting, which is cheaper and safe for messages containing
+// '%'.
+
+// Infoln writes a log on info level, formatted as fmt.Sprintln
+func Infoln(v ...interface{}) {
+	globLogger.loglevel(LvlInfo, msgPrintln, "", v)
+}
+
+// InfoMsg writes a log on info level, with msg as is
+func InfoMsg(msg string) {
+	globLogger.loglevel(LvlInfo, msgPlain, msg, nil)
+}
+
+// Warnln writes a log on warn level, formatted as fmt.Sprintln
+func Warnln(v ...interface{}) {
+	globLogger.loglevel(LvlWarn, msgPrintln, "", v)
+}
+
+// WarnMsg writes a log on warn level, with msg as is
+func WarnMsg(msg string) {
+	globLogger.loglevel(LvlWarn, msgPlain, msg, nil)
+}
+
+// Errorln writes a log on error level, formatted as fmt.Sprintln
+func Errorln(v ...interface{}) {
+	globLogger.loglevel(LvlError, msgPrintln, "", v)
+}
+
+// ErrorMsg writes a log on error level, with msg as is
+func ErrorMsg(msg string) {
+	globLogger.loglevel(LvlError, msgPlain, msg, nil)
+}
+
+// Panicln writes a log on panic level, formatted as fmt.Sprintln, flush
+// the log and calls panic()
+func Panicln(v ...interface{}) {
+	globLogger.panic(msgPrintln, "", v)
+}
+
+// PanicMsg writes a log on panic level, with msg as is, flush the log
+// and calls panic()
+func PanicMsg(msg string) {
+	globLogger.panic(msgPlain, msg, nil)
+}
+
+// Auditln writes a log on audit level, formatted as fmt.Sprintln. Audit
+// entries are always written, regardless of the level set with SetLevel.
+func Auditln(v ...interface{}) {
+	globLogger.output(2, LvlAudit, msgPrintln, "", v)
+}
+
+// AuditMsg writes a log on audit level, with msg as is. Audit entries
+// are always written, regardless of the level set with SetLevel.
+func AuditMsg(msg string) {
+	globLogger.output(2, LvlAudit, msgPlain, msg, nil)
+}
+
+// Infoln writes a log on info level, formatted as fmt.Sprintln
+func (l *Logger) Infoln(v ...interface{}) {
+	l.loglevel(LvlInfo, msgPrintln, "", v)
+}
+
+// InfoMsg writes a log on info level, with msg as is
+func (l *Logger) InfoMsg(msg string) {
+	l.loglevel(LvlInfo, msgPlain, msg, nil)
+}
+
+// Warnln writes a log on warn level, formatted as fmt.Sprintln
+func (l *Logger) Warnln(v ...interface{}) {
+	l.loglevel(LvlWarn, msgPrintln, "", v)
+}
+
+// WarnMsg writes a log on warn level, with msg as is
+func (l *Logger) WarnMsg(msg string) {
+	l.loglevel(LvlWarn, msgPlain, msg, nil)
+}
+
+// Errorln writes a log on error level, formatted as fmt.Sprintln
+func (l *Logger) Errorln(v ...interface{}) {
+	l.loglevel(LvlError, msgPrintln, "", v)
+}
+
+// ErrorMsg writes a log on error level, with msg as is
+func (l *Logger) ErrorMsg(msg string) {
+	l.loglevel(LvlError, msgPlain, msg, nil)
+}
+
+// Panicln writes a log on panic level, formatted as fmt.Sprintln, flush
+// the log and calls panic()
+func (l *Logger) Panicln(v ...interface{}) {
+	l.panic(msgPrintln, "", v)
+}
+
+// PanicMsg writes a log on panic level, with msg as is, flush the log
+// and calls panic()
+func (l *Logger) PanicMsg(msg string) {
+	l.panic(msgPlain, msg, nil)
+}
+
+// Auditln writes a log on audit level, formatted as fmt.Sprintln. Audit
+// entries are always written, regardless of the level set with SetLevel.
+func (l *Logger) Auditln(v ...interface{}) {
+	l.output(2, LvlAudit, msgPrintln, "", v)
+}
+
+// AuditMsg writes a log on audit level, with msg as is. Audit entries
+// are always written, regardless of the level set with SetLevel.
+func (l *Logger) AuditMsg(msg string) {
+	l.output(2, LvlAudit, msgPlain, msg, nil)
+}
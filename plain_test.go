@@ -0,0 +1,60 @@
+// Unit tests for the ln and Msg variants
+package llog
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPlainVariants(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+
+	Infoln("connections:", 42, "of", 100)
+	InfoMsg("100% done")
+	DebugMsg("not written")
+	WithWorker("w1").Warnln("retry", 3)
+	ErrorMsg("failed %d")
+	AuditMsg("user %s")
+	func() {
+		defer func() {
+			if r := recover(); r != "disk 100% full" {
+				t.Errorf("Wrong panic value: %v", r)
+			}
+		}()
+		PanicMsg("disk 100% full")
+	}()
+
+	output := buffer.String()
+	for _, expected := range []string{
+		"plain_test.go:19: INFO - connections: 42 of 100\n",
+		"plain_test.go:20: INFO - 100% done\n",
+		"plain_test.go:22: [w1] WARN - retry 3\n",
+		"plain_test.go:23: ERROR - failed %d\n",
+		"plain_test.go:24: AUDIT - user %s\n",
+		"plain_test.go:31: PANIC - disk 100% full\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Fatalf("Expected %q in output: %s", expected, output)
+		}
+	}
+	if strings.Contains(output, "not written") {
+		t.Fatalf("Level not respected: %s", output)
+	}
+}
+
+func BenchmarkInfoMsg(b *testing.B) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		InfoMsg("connection established")
+	}
+}
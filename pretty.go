@@ -0,0 +1,141 @@
+package llog
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// globPretty is the state of FormatPretty or nil, see SetFormat
+var globPretty *prettyFormat
+
+// prettyFormat writes entries in FormatPretty
+type prettyFormat struct {
+	started   time.Time    // relative timestamps are since started
+	color     bool         // false if the NO_COLOR environment variable is set
+	width     atomic.Int32 // width of the widest caller so far
+	terminals sync.Map     // *os.File to true if a terminal
+}
+
+// newPrettyFormat returns the state of FormatPretty, set at now.
+func newPrettyFormat(now time.Time) *prettyFormat {
+	return &prettyFormat{started: now, color: os.Getenv("NO_COLOR") == ""}
+}
+
+// isTerminal returns true if file is a terminal. Replaced by tests.
+var isTerminal = func(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// colored returns true if entries written to w shall be colored, which
+// they are if w is a terminal, unless the NO_COLOR environment variable
+// is set.
+func (p *prettyFormat) colored(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !p.color || !ok {
+		return false
+	}
+	if terminal, ok := p.terminals.Load(file); ok {
+		return terminal.(bool)
+	}
+	terminal := isTerminal(file)
+	p.terminals.Store(file, terminal)
+	return terminal
+}
+
+// levelColors are the ANSI escape sequences of the levels
+var levelColors = map[Level]string{
+	LvlTrace: "\x1b[90m",
+	LvlDebug: "\x1b[36m",
+	LvlInfo:  "\x1b[32m",
+	LvlWarn:  "\x1b[33m",
+	LvlError: "\x1b[31m",
+	LvlPanic: "\x1b[1;31m",
+	LvlAudit: "\x1b[35m",
+}
+
+// Escape sequences for dimmed text and to reset the color
+const (
+	colorDim   = "\x1b[2m"
+	colorReset = "\x1b[0m"
+)
+
+// appendPretty appends the entry in FormatPretty, with ANSI colors if
+// color is true:
+//
+//	12.345 INFO  main.go:23  #42 [conn-42] message | key=value
+func (p *prettyFormat) appendPretty(b []byte, e *Entry, color bool) []byte {
+	if color {
+		b = append(b, colorDim...)
+	}
+	var num [32]byte
+	elapsed := strconv.AppendFloat(num[:0], e.Time.Sub(p.started).Seconds(), 'f', 3, 64)
+	b = appendPadding(b, 8-len(elapsed))
+	b = append(b, elapsed...)
+	if color {
+		b = append(b, colorReset...)
+	}
+	b = append(b, ' ')
+
+	levelColor, ok := levelColors[e.Level]
+	if color && ok {
+		b = append(b, levelColor...)
+	}
+	level := e.Level.String()
+	b = append(b, level...)
+	if color && ok {
+		b = append(b, colorReset...)
+	}
+	b = appendPadding(b, 6-len(level))
+
+	if e.File != "???" && e.File != "" {
+		start := len(b)
+		b = append(b, shortFile(e.File)...)
+		b = append(b, ':')
+		b = strconv.AppendInt(b, int64(e.Line), 10)
+		width := int32(len(b) - start)
+		for {
+			widest := p.width.Load()
+			if width <= widest || p.width.CompareAndSwap(widest, width) {
+				width = max(width, widest)
+				break
+			}
+		}
+		b = appendPadding(b, int(width)-(len(b)-start)+2)
+	}
+
+	if e.Seq != 0 {
+		b = append(b, '#')
+		b = strconv.AppendUint(b, e.Seq, 10)
+		b = append(b, ' ')
+	}
+	if e.Worker != "" {
+		b = append(b, '[')
+		b = append(b, e.Worker...)
+		b = append(b, "] "...)
+	}
+	b = append(b, e.Message...)
+	if len(e.Fields) > 0 && color {
+		b = append(b, colorDim...)
+		b = appendFields(b, e.Fields)
+		b = append(b, colorReset...)
+	} else {
+		b = appendFields(b, e.Fields)
+	}
+	if len(b) == 0 || b[len(b)-1] != '\n' {
+		b = append(b, '\n')
+	}
+	return b
+}
+
+// appendPadding appends n spaces.
+func appendPadding(b []byte, n int) []byte {
+	for ; n > 0; n-- {
+		b = append(b, ' ')
+	}
+	return b
+}
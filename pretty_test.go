@@ -0,0 +1,81 @@
+// Unit tests for pretty
+package llog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatPretty(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	clock := time.Date(2001, 2, 3, 4, 5, 6, 0, time.Local)
+	SetClock(func() time.Time { return clock })
+	defer SetClock(nil)
+	t.Setenv("NO_COLOR", "1")
+	SetFormat(FormatPretty)
+	defer SetFormat(FormatText)
+
+	clock = clock.Add(1250 * time.Millisecond)
+	Info("first")
+	_, line, _ := caller(0)
+	clock = clock.Add(2 * time.Second)
+	WithWorker("w1").Warn("second")
+	WriteBatch([]Entry{{Level: LvlError, File: "/src/handler.go", Line: 1234, Message: "batch",
+		Fields: []Field{{Key: "k", Value: "v"}}}})
+
+	want := fmt.Sprintf("   1.250 INFO  pretty_test.go:%d  first\n", line-1) +
+		fmt.Sprintf("   3.250 WARN  pretty_test.go:%d  [w1] second\n", line+2) +
+		"   3.250 ERROR handler.go:1234    batch | k=v\n" // Aligned with the widest caller
+	if buffer.String() != want {
+		t.Fatalf("Got:\n%s\nWant:\n%s", buffer.String(), want)
+	}
+
+	// Colored levels on terminals only
+	t.Setenv("NO_COLOR", "")
+	SetFormat(FormatPretty)
+	buffer.Reset()
+	Error("not a terminal")
+	if strings.Contains(buffer.String(), "\x1b[") {
+		t.Fatalf("Colored when not a terminal: %q", buffer.String())
+	}
+	r, w, _ := os.Pipe()
+	defer r.Close()
+	defer func(f func(*os.File) bool) { isTerminal = f }(isTerminal)
+	isTerminal = func(file *os.File) bool { return file == w }
+	log.SetOutput(w)
+	Error("colored")
+	w.Close()
+	log.SetOutput(buffer)
+	if colored, _ := io.ReadAll(r); !strings.Contains(string(colored), "\x1b[31mERROR\x1b[0m") {
+		t.Fatalf("Level not colored: %q", colored)
+	}
+
+	// Sequence numbers
+	SetSequenceNumbers(true)
+	Info("numbered")
+	SetSequenceNumbers(false)
+	if !strings.Contains(buffer.String(), "  #") || !strings.HasSuffix(buffer.String(), " numbered\n") {
+		t.Fatalf("Sequence number not written: %q", buffer.String())
+	}
+
+	// Log files are written in the text format
+	fileName := filepath.Join(t.TempDir(), "pretty.log")
+	if err := SetFile(fileName, 100); err != nil {
+		t.Fatalf("Unable to set file. Reason: %s", err)
+	}
+	Info("to file")
+	Close()
+	if content, _ := os.ReadFile(fileName); !strings.HasSuffix(string(content), "INFO - to file\n") {
+		t.Fatalf("File not in text format: %q", content)
+	}
+}
@@ -0,0 +1,118 @@
+package llog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// globProgressInterval is the min interval between progress entries,
+// see SetProgressInterval
+var globProgressInterval = 10 * time.Second
+
+// SetProgressInterval sets the min interval between the entries written
+// by Update of a ProgressLog (default 10 seconds). Zero writes an entry
+// for each update.
+func SetProgressInterval(interval time.Duration) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globProgressInterval = interval
+}
+
+// ProgressLog writes the progress of a long operation, see Progress. It
+// is safe for concurrent use.
+type ProgressLog struct {
+	l        *Logger
+	level    Level
+	msg      string
+	interval time.Duration
+	mutex    sync.Mutex
+	started  time.Time
+	logged   time.Time // time of the latest progress entry
+	done     int64
+	finished bool
+}
+
+// Progress returns a ProgressLog writing the progress of a long
+// operation, for example a batch job, on level with the message created
+// from format and v:
+//
+//	p := llog.Progress(llog.LvlInfo, "Migrating %d rows", n)
+//	for i := range rows {
+//		migrate(rows[i])
+//		p.Update(i + 1) // INFO - Migrating 5000 rows: 1200 done in 10s (120.0/s)
+//	}
+//	p.Done() // INFO - Migrating 5000 rows: finished, 5000 done in 41.5s
+//
+// Update writes at most one entry per interval, see SetProgressInterval.
+func Progress(level Level, format string, v ...interface{}) *ProgressLog {
+	return globLogger.Progress(level, format, v...)
+}
+
+// Progress as the package function Progress, with the worker tag of the
+// logger.
+func (l *Logger) Progress(level Level, format string, v ...interface{}) *ProgressLog {
+	globMutex.Lock()
+	interval := globProgressInterval
+	globMutex.Unlock()
+	started := now()
+	return &ProgressLog{
+		l:        l,
+		level:    level,
+		msg:      string(appendMessage(nil, msgPrintf, format, v)),
+		interval: interval,
+		started:  started,
+		logged:   started,
+	}
+}
+
+// Update sets the number of items done and writes an entry with the
+// progress if the interval since the previous entry has passed.
+func (p *ProgressLog) Update(done int) {
+	p.mutex.Lock()
+	p.done = int64(done)
+	t := now()
+	if p.finished || t.Sub(p.logged) < p.interval {
+		p.mutex.Unlock()
+		return
+	}
+	p.logged = t
+	elapsed := t.Sub(p.started)
+	msg := fmt.Sprintf("%s: %d done in %s", p.msg, done, roundDuration(elapsed))
+	if elapsed > 0 {
+		msg += fmt.Sprintf(" (%.1f/s)", float64(done)/elapsed.Seconds())
+	}
+	p.mutex.Unlock()
+	p.write(msg)
+}
+
+// Done writes an entry with the number of items done and the total
+// duration of the operation. Only the first call has any effect.
+func (p *ProgressLog) Done() {
+	p.mutex.Lock()
+	if p.finished {
+		p.mutex.Unlock()
+		return
+	}
+	p.finished = true
+	msg := fmt.Sprintf("%s: finished, %d done in %s", p.msg, p.done,
+		roundDuration(now().Sub(p.started)))
+	p.mutex.Unlock()
+	p.write(msg)
+}
+
+// write is called by Update and Done with the same call depth as
+// loglevel.
+func (p *ProgressLog) write(msg string) {
+	if level := p.l.eventLevel(p.level); level >= levelSet() {
+		p.l.output(3, level, msgPlain, msg, nil)
+	}
+}
+
+// roundDuration rounds d for progress entries.
+func roundDuration(d time.Duration) time.Duration {
+	if d >= time.Minute {
+		return d.Round(time.Second)
+	}
+	return d.Round(time.Millisecond)
+}
@@ -0,0 +1,57 @@
+// Unit tests for progress
+package llog
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	clock := time.Date(2001, 2, 3, 4, 5, 6, 0, time.Local)
+	SetClock(func() time.Time { return clock })
+	defer SetClock(nil)
+	SetProgressInterval(10 * time.Second)
+
+	p := Progress(LvlInfo, "Migrating %d rows", 100)
+	for i := 1; i <= 100; i++ {
+		clock = clock.Add(time.Second)
+		p.Update(i) // Written at 10, 20, ..., 100 seconds
+	}
+	clock = clock.Add(500 * time.Millisecond)
+	p.Done()
+	p.Done()
+	WithWorker("w1").Progress(LvlDebug, "Hidden").Done()
+
+	entries := readEntries(t, buffer)
+	if len(entries) != 11 {
+		t.Fatalf("Expected 11 entries, got %d: %q", len(entries), entries)
+	}
+	if entries[0].Message != "Migrating 100 rows: 10 done in 10s (1.0/s)" ||
+		entries[9].Message != "Migrating 100 rows: 100 done in 1m40s (1.0/s)" ||
+		entries[10].Message != "Migrating 100 rows: finished, 100 done in 1m41s" {
+		t.Fatalf("Wrong progress: %q", entries)
+	}
+	if shortFile(entries[0].File) != "progress_test.go" {
+		t.Fatalf("Wrong caller %s", entries[0].File)
+	}
+
+	// Each update is written with interval zero
+	buffer.Reset()
+	SetProgressInterval(0)
+	defer SetProgressInterval(10 * time.Second)
+	p = Progress(LvlWarn, "Copying")
+	p.Update(1)
+	p.Update(2)
+	p.Done()
+	if entries := readEntries(t, buffer); len(entries) != 3 || entries[2].Level != LvlWarn ||
+		entries[2].Message != "Copying: finished, 2 done in 0s" {
+		t.Fatalf("Wrong progress: %q", entries)
+	}
+}
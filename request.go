@@ -0,0 +1,51 @@
+package llog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// requestIDKey is the context key of the request ID
+type requestIDKey struct{}
+
+// ForRequest returns a logger, and a context, for a request. Entries
+// written by the logger have the request ID of the context in the field
+// request_id, so that all entries of a request can be correlated. If ctx
+// has no request ID a new short random ID is generated and stored in the
+// returned context, which shall be passed on to the handling of the
+// request. The logger also writes the trace fields of ctx, as
+// WithContext.
+//
+//	log, ctx := llog.ForRequest(r.Context())
+//	log.Info("Order placed") // ... INFO - Order placed | request_id=5f2b9c0e41d7
+func ForRequest(ctx context.Context) (*Logger, context.Context) {
+	return globLogger.ForRequest(ctx)
+}
+
+// ForRequest returns a copy of the logger, and a context, for a request,
+// as the package function ForRequest.
+func (l *Logger) ForRequest(ctx context.Context) (*Logger, context.Context) {
+	id := RequestID(ctx)
+	if id == "" {
+		id = newRequestID()
+		ctx = context.WithValue(ctx, requestIDKey{}, id)
+	}
+	c := l.WithContext(ctx)
+	c.requestID = id
+	return c, ctx
+}
+
+// RequestID returns the request ID of the context, see ForRequest, or
+// empty if none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// newRequestID returns a new random request ID of 12 hex digits.
+func newRequestID() string {
+	var b [6]byte
+	rand.Read(b[:]) // Never fails
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,46 @@
+// Unit tests for request
+package llog
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestForRequest(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+
+	reqLog, ctx := ForRequest(context.Background())
+	id := RequestID(ctx)
+	if len(id) != 12 {
+		t.Fatalf("Request ID not generated: %q", id)
+	}
+	reqLog.Info("handling")
+	sameLog, sameCtx := WithWorker("w1").ForRequest(ctx)
+	if sameCtx != ctx {
+		t.Fatal("Context with request ID replaced")
+	}
+	sameLog.WithEvent(evTest).Warn("nested")
+	otherLog, _ := ForRequest(context.Background())
+	otherLog.Info("other")
+
+	entries := readEntries(t, buffer)
+	if len(entries) != 3 || len(entries[0].Fields) != 1 || entries[0].Fields[0] != (Field{Key: "request_id", Value: id}) {
+		t.Fatalf("Request ID not written: %v", entries)
+	}
+	if entries[1].Worker != "w1" || len(entries[1].Fields) != 2 || entries[1].Event() != evTest ||
+		entries[1].Fields[1].Value != id {
+		t.Fatalf("Request ID not kept: %v", entries[1])
+	}
+	if entries[2].Fields[0].Value == id {
+		t.Fatalf("Same request ID generated twice: %v", entries[2])
+	}
+	if RequestID(context.Background()) != "" {
+		t.Fatal("Request ID returned without request")
+	}
+}
@@ -0,0 +1,113 @@
+package llog
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// globSanitize is true if messages are sanitized, see SetSanitize
+var globSanitize bool
+
+// SetSanitize escapes control characters, except tab, and invalid UTF-8
+// in messages, worker tags and field values written in the text format,
+// which prevents log injection, for example an attacker supplied newline
+// forging a fake entry, and keeps log files viewable in terminals.
+// Newlines are written as \n, backslashes as \\, other control
+// characters below U+0080 and invalid bytes as \xNN, and C1 control
+// characters and the line and paragraph separators U+2028 and U+2029 as
+// \uNNNN:
+//
+//	2009/01/23 01:23:23 file.go:23: WARN - login failed for admin\n2009/01/23 01:23:24 file.go:23: INFO - login ok
+//
+// Multi line messages are written on a single line when enabled. The
+// JSON format, see FormatJSON, always escapes control characters and
+// replaces invalid UTF-8. Default is disabled.
+func SetSanitize(enable bool) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globSanitize = enable
+	updateOutput()
+}
+
+// sanitizeMessage escapes control characters and invalid UTF-8 in the
+// message in b, starting at start.
+func sanitizeMessage(b []byte, start int) []byte {
+	for i := start; i < len(b); i++ {
+		if mightEscape(b[i]) {
+			return append(b[:i], appendSanitized(nil, b[i:])...)
+		}
+	}
+	return b // Fast path, nothing to escape
+}
+
+// sanitizeString returns s with control characters and invalid UTF-8
+// escaped, as sanitizeMessage.
+func sanitizeString(s string) string {
+	for i := 0; i < len(s); i++ {
+		if mightEscape(s[i]) {
+			return s[:i] + string(appendSanitized(nil, []byte(s[i:])))
+		}
+	}
+	return s
+}
+
+// sanitizeFields returns the fields with the values sanitized. The
+// fields are copied if any value is changed.
+func sanitizeFields(fields []Field) []Field {
+	copied := false
+	for i := range fields {
+		if value := sanitizeString(fields[i].Value); value != fields[i].Value {
+			if !copied {
+				fields = append([]Field(nil), fields...)
+				copied = true
+			}
+			fields[i].Value = value
+		}
+	}
+	return fields
+}
+
+// mightEscape returns true if c might start a character which is escaped
+// when sanitized.
+func mightEscape(c byte) bool {
+	return (c < ' ' && c != '\t') || c == '\\' || c == 0x7f || c >= utf8.RuneSelf
+}
+
+// appendSanitized appends msg with control characters and invalid UTF-8
+// escaped.
+func appendSanitized(b []byte, msg []byte) []byte {
+	for len(msg) > 0 {
+		r, size := utf8.DecodeRune(msg)
+		switch {
+		case r == '\n':
+			b = append(b, `\n`...)
+		case r == '\r':
+			b = append(b, `\r`...)
+		case r == '\\':
+			b = append(b, `\\`...)
+		case (r >= 0x80 && r <= 0x9f) || r == '\u2028' || r == '\u2029':
+			b = append(b, `\u`...)
+			b = appendHex(b, uint64(r), 4)
+		case r == '\t' || (r >= ' ' && r != 0x7f && r != utf8.RuneError):
+			b = append(b, msg[:size]...)
+		case r == utf8.RuneError && size == 3:
+			b = append(b, msg[:size]...) // Valid encoding of U+FFFD
+		default:
+			for _, c := range msg[:size] {
+				b = append(b, `\x`...)
+				b = appendHex(b, uint64(c), 2)
+			}
+		}
+		msg = msg[size:]
+	}
+	return b
+}
+
+// appendHex appends i in hexadecimal, zero padded to width digits.
+func appendHex(b []byte, i uint64, width int) []byte {
+	hex := strconv.FormatUint(i, 16)
+	for n := len(hex); n < width; n++ {
+		b = append(b, '0')
+	}
+	return append(b, hex...)
+}
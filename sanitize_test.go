@@ -0,0 +1,39 @@
+// Unit tests for sanitize
+package llog
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	SetSanitize(true)
+	defer SetSanitize(false)
+
+	Warn("login failed for %s", "admin\n2009/01/23 01:23:24 file.go:23: INFO - login ok")
+	InfoMsg("tab\tbell\a esc\x1b[31m del\x7f åäö \xff\xfe �")
+	Info("clean")
+	WriteBatch([]Entry{{Level: LvlInfo, Message: "batch\nforged"}})
+	InfoMsg(`back\n slash` + " c1\u0085\u009b[31m sep\u2028\u2029")
+	WithWorker("w\n1").WithEvent("E\x1b1").Info("tagged")
+	fields := []Field{{Key: "key", Value: "v\n1"}}
+	WriteBatch([]Entry{{Level: LvlInfo, Worker: "b\r1", Message: "batch", Fields: fields}})
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if len(lines) != 7 ||
+		!strings.HasSuffix(lines[0], `WARN - login failed for admin\n2009/01/23 01:23:24 file.go:23: INFO - login ok`) ||
+		!strings.HasSuffix(lines[1], `INFO - tab`+"\t"+`bell\x07 esc\x1b[31m del\x7f åäö \xff\xfe �`) ||
+		!strings.HasSuffix(lines[2], "INFO - clean") || !strings.HasSuffix(lines[3], `INFO - batch\nforged`) ||
+		!strings.HasSuffix(lines[4], `INFO - back\\n slash c1\u0085\u009b[31m sep\u2028\u2029`) ||
+		!strings.HasSuffix(lines[5], `[w\n1] INFO - tagged | event=E\x1b1`) ||
+		!strings.HasSuffix(lines[6], `[b\r1] INFO - batch | key=v\n1`) {
+		t.Fatalf("Messages not sanitized:\n%s", buffer.String())
+	}
+}
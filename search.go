@@ -0,0 +1,96 @@
+package llog
+
+import (
+	"crypto/cipher"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"time"
+)
+
+// Query selects entries in Search. Zero values match all entries.
+type Query struct {
+	// Since and Until selects entries written within this time
+	Since time.Time
+	Until time.Time
+	// MinLevel is the lowest level selected
+	MinLevel Level
+	// Pattern is a regular expression that the message shall match
+	Pattern string
+	// File is the log file searched, with its backup file, instead of the
+	// log file set with SetFile, for example by a tool analyzing log
+	// files. It shall be in the text or JSON format.
+	File string
+}
+
+// Search returns the entries in the log file set with SetFile, or the
+// file of the query, and its backup file, selected by the query. The
+// entries are returned oldest first. Encrypted log files are decrypted
+// and binary log files, see SetFormat, decoded. The times in the text
+// format are read as UTC if the LUTC flag is set in the log package.
+func Search(q Query) ([]Entry, error) {
+	var pattern *regexp.Regexp
+	if q.Pattern != "" {
+		var err error
+		if pattern, err = regexp.Compile(q.Pattern); err != nil {
+			return nil, err
+		}
+	}
+	fileName, aead, binary := q.File, cipher.AEAD(nil), false
+	if fileName == "" {
+		globMutex.Lock()
+		file := globFile
+		globMutex.Unlock()
+		if file == nil {
+			return nil, errors.New("llog: not logging to a file")
+		}
+		file.Flush()
+		fileName, aead, binary = file.name, file.aead, file.binary != nil
+	}
+	location := time.Local
+	if log.Flags()&log.LUTC != 0 {
+		location = time.UTC
+	}
+
+	var entries []Entry
+	for _, fileName := range []string{fileName + ".1", fileName} {
+		reader, err := openLogReader(fileName, aead)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return entries, err
+		}
+		textReader := NewEntryReader(reader)
+		textReader.SetLocation(location)
+		var entryReader interface{ Next() (Entry, error) } = textReader
+		if binary {
+			entryReader = NewBinaryReader(reader)
+		}
+		for {
+			e, err := entryReader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				reader.Close()
+				return entries, err
+			}
+			if q.matches(e, pattern) {
+				entries = append(entries, e)
+			}
+		}
+		reader.Close()
+	}
+	return entries, nil
+}
+
+// matches returns true if the entry is selected by the query.
+func (q Query) matches(e Entry, pattern *regexp.Regexp) bool {
+	return e.Level >= q.MinLevel &&
+		(q.Since.IsZero() || !e.Time.Before(q.Since)) &&
+		(q.Until.IsZero() || !e.Time.After(q.Until)) &&
+		(pattern == nil || pattern.MatchString(e.Message))
+}
@@ -0,0 +1,87 @@
+// Unit tests for search
+package llog
+
+import (
+	"log"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSearch(t *testing.T) {
+	logFileName := "searchlog.txt"
+	backupFileName := logFileName + ".1"
+	os.Remove(logFileName)
+	os.Remove(backupFileName)
+	if _, err := Search(Query{}); err == nil {
+		t.Fatalf("Search without log file shall give an error")
+	}
+	SetFileEncryption([]byte("0123456789abcdef"))
+	defer SetFileEncryption(nil)
+	SetLevel(LvlInfo)
+	start := time.Now().Add(-time.Second)
+	if err := SetFile(logFileName, 5); err != nil {
+		t.Fatalf("Unable to log to file. Reason: %s", err)
+	}
+	Error("error %d", 0)
+	for i := 0; i < 100; i++ {
+		Info("entry %d", i)
+	}
+	Error("error %d", 1)
+	Warn("warning")
+
+	entries, err := Search(Query{MinLevel: LvlWarn})
+	if err != nil {
+		t.Fatalf("Unable to search. Reason: %s", err)
+	}
+	if !fileExist(backupFileName) {
+		t.Fatalf("Log file was never wrapped")
+	}
+	if len(entries) != 3 || entries[0].Message != "error 0" || entries[2].Message != "warning" {
+		t.Fatalf("Search in backup and log file not correct: %v", entries)
+	}
+	entries, _ = Search(Query{Since: start, Until: time.Now().Add(time.Second), Pattern: `^entry \d*5$`})
+	if len(entries) != 10 {
+		t.Fatalf("Search with pattern not correct: %v", entries)
+	}
+	entries, _ = Search(Query{Since: time.Now().Add(time.Hour)})
+	if len(entries) != 0 {
+		t.Fatalf("Search since not correct: %v", entries)
+	}
+	if _, err := Search(Query{Pattern: "("}); err == nil {
+		t.Fatalf("Invalid pattern shall give an error")
+	}
+
+	// Cleanup
+	log.SetOutput(os.Stderr)
+	globFile.Close()
+	globFile = nil
+	updateOutput()
+	os.Remove(logFileName)
+	os.Remove(backupFileName)
+}
+
+func TestSearchFile(t *testing.T) {
+	fileName := "searchfile.txt"
+	os.WriteFile(fileName+".1", []byte("2019/01/26 22:57:15 a.go:1: INFO - old\n"), 0666)
+	os.WriteFile(fileName, []byte("2019/01/26 22:57:16 a.go:1: INFO - new\n"), 0666)
+	defer os.Remove(fileName)
+	defer os.Remove(fileName + ".1")
+	defer log.SetFlags(log.Flags())
+	log.SetFlags(log.LstdFlags | log.LUTC)
+
+	entries, err := Search(Query{File: fileName})
+	if err != nil {
+		t.Fatalf("Unable to search. Reason: %s", err)
+	}
+	if len(entries) != 2 || entries[0].Message != "old" || entries[1].Message != "new" ||
+		!entries[1].Time.Equal(time.Date(2019, 1, 26, 22, 57, 16, 0, time.UTC)) {
+		t.Fatalf("Entries not correct: %v", entries)
+	}
+
+	os.Remove(fileName + ".1")
+	entries, err = Search(Query{File: fileName})
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Missing backup shall be ignored: %v %s", entries, err)
+	}
+}
@@ -0,0 +1,34 @@
+package llog
+
+import "sync/atomic"
+
+// globSequenceEnabled is true if sequence numbers are written
+var globSequenceEnabled bool
+
+// globSequence is the sequence number of the last entry written to the
+// log and globAuditSequence the last entry written to the audit file
+var globSequence, globAuditSequence atomic.Uint64
+
+// SetSequenceNumbers writes an incrementing sequence number before the
+// level of each entry:
+//
+//	2009/01/23 01:23:23 file.go:23: #42 INFO - message
+//
+// A gap in the sequence tells that entries have been lost, and entries
+// with the same time can be ordered. The audit file set with
+// SetAuditFile has a sequence of its own. The first entry has sequence
+// number 1.
+func SetSequenceNumbers(enable bool) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globSequenceEnabled = enable
+	updateOutput()
+}
+
+// nextSeq returns the next sequence number of the audit file or the log.
+func nextSeq(audit bool) uint64 {
+	if audit {
+		return globAuditSequence.Add(1)
+	}
+	return globSequence.Add(1)
+}
@@ -0,0 +1,72 @@
+// Unit tests for sequence numbers
+package llog
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSequenceNumbers(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+
+	Info("without")
+	SetSequenceNumbers(true)
+	defer SetSequenceNumbers(false)
+	first := globSequence.Load() + 1
+	Info("first")
+	Warn("second")
+
+	entries := readEntries(t, buffer)
+	if len(entries) != 3 || entries[0].Seq != 0 {
+		t.Fatalf("Sequence number written when not enabled: %v", entries)
+	}
+	if entries[1].Seq != first || entries[2].Seq != first+1 {
+		t.Fatalf("Sequence numbers not correct: %v", entries)
+	}
+	if !strings.Contains(entries[2].String(), " #") {
+		t.Fatalf("Sequence number missing in String: %s", entries[2])
+	}
+}
+
+// readEntries reads all entries written to the buffer.
+func readEntries(t *testing.T, buffer *bytes.Buffer) []Entry {
+	t.Helper()
+	var entries []Entry
+	reader := NewEntryReader(buffer)
+	for {
+		e, err := reader.Next()
+		if err != nil {
+			return entries
+		}
+		entries = append(entries, e)
+	}
+}
+
+func TestSequenceNumbersOutputLevel(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	SetOutputLevel(LvlWarn)
+	defer SetOutputLevel(0)
+	SetSequenceNumbers(true)
+	defer SetSequenceNumbers(false)
+	sink := &testSink{}
+	AddSink(sink)
+	defer RemoveSink(sink)
+
+	Warn("a")
+	Info("only to sink")
+	WriteBatch([]Entry{{Level: LvlInfo, Message: "batch only to sink"}, {Level: LvlWarn, Message: "b"}})
+	Warn("c")
+	entries := readEntries(t, buffer)
+	if len(entries) != 3 || entries[1].Seq != entries[0].Seq+1 || entries[2].Seq != entries[0].Seq+2 {
+		t.Fatalf("Entries not written numbered: %v", entries)
+	}
+}
@@ -0,0 +1,31 @@
+package llog
+
+import "context"
+
+// globStopped is true when Shutdown has been called
+var globStopped bool
+
+// Shutdown stops writing entries, sends the entries queued by the sinks
+// and flushes, syncs and closes the log files, as Close. Entries written
+// after Shutdown is called are discarded, so that the last entry before
+// Shutdown is the last entry in the log.
+//
+// If ctx is done before the log is closed, Shutdown returns the error of
+// ctx while closing continues in the background.
+func Shutdown(ctx context.Context) error {
+	globMutex.Lock()
+	globStopped = true
+	updateOutput()
+	globMutex.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- Close()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,79 @@
+// Unit tests for shutdown
+package llog
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// blockingSink is a sink which Close blocks until release is closed
+type blockingSink struct {
+	testSink
+	release chan struct{}
+}
+
+func (s *blockingSink) Close() error {
+	<-s.release
+	return s.testSink.Close()
+}
+
+// restart makes llog write entries again after Shutdown.
+func restart() {
+	globMutex.Lock()
+	globStopped = false
+	updateOutput()
+	globMutex.Unlock()
+}
+
+func TestShutdown(t *testing.T) {
+	logFileName := "shutdownlog.txt"
+	os.Remove(logFileName)
+	defer os.Remove(logFileName)
+	SetLevel(LvlInfo)
+	SetBuffering(4096, 0)
+	defer SetBuffering(0, 0)
+	if err := SetFile(logFileName, 1024); err != nil {
+		t.Fatalf("Unable to log to file. Reason: %s", err)
+	}
+	sink := &testSink{}
+	AddSink(sink)
+
+	Error("last error")
+	err := Shutdown(context.Background())
+	defer restart()
+	if err != nil {
+		t.Fatalf("Unable to shutdown. Reason: %s", err)
+	}
+	Error("after shutdown")
+	content, _ := os.ReadFile(logFileName)
+	if !strings.Contains(string(content), "last error") || strings.Contains(string(content), "after shutdown") {
+		t.Fatalf("Log file not correct after shutdown: %s", content)
+	}
+	if !sink.isClosed() || len(sink.messages()) != 1 {
+		t.Fatalf("Sink not closed: %v", sink.messages())
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	SetLevel(LvlInfo)
+	sink := &blockingSink{release: make(chan struct{})}
+	AddSink(sink)
+	defer restart()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown shall return deadline exceeded, got %v", err)
+	}
+	close(sink.release)
+	for i := 0; i < 100 && !sink.isClosed(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !sink.isClosed() {
+		t.Fatal("Sink not closed in background")
+	}
+}
@@ -0,0 +1,102 @@
+package llog
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// Sink receives each entry written to the log, in addition to the log
+// output, for example to send the entries to a log service.
+type Sink interface {
+	// Write is called with each entry written to the log. Write is
+	// called while logging, so a sink that is slow shall queue the
+	// entry and return directly.
+	Write(e Entry) error
+	// Close writes any queued entries and releases the resources of the
+	// sink.
+	Close() error
+}
+
+// sinkHandler is a sink added with AddSink
+type sinkHandler struct {
+	sink     Sink
+	handler  entryHandler
+	minLevel atomic.Int32 // see SetSinkLevel
+}
+
+// globSinks are the sinks added with AddSink
+var globSinks []*sinkHandler
+
+// AddSink makes the sink receive each entry written to the log. Errors
+// returned by the sink are reported to the error handler, see
+// SetErrorHandler.
+func AddSink(sink Sink) {
+	s := &sinkHandler{sink: sink}
+	s.handler.handle = func(e *Entry) {
+		if e.Level < Level(s.minLevel.Load()) && e.Level != LvlAudit {
+			return
+		}
+		if err := sink.Write(*e); err != nil {
+			reportError(fmt.Errorf("llog: unable to write to sink: %w", err))
+		}
+	}
+	globMutex.Lock()
+	globSinks = append(globSinks, s)
+	globMutex.Unlock()
+	addHandler(&s.handler)
+}
+
+// SetSinkLevel sets the lowest level written to a sink added with
+// AddSink, when it shall be higher than the level set with SetLevel, for
+// example to only send warnings and errors to a log service while the
+// log file gets all entries. Audit entries are always written. Level 0
+// writes all entries (default).
+func SetSinkLevel(sink Sink, level Level) error {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	for _, s := range globSinks {
+		if s.sink == sink {
+			s.minLevel.Store(int32(level))
+			return nil
+		}
+	}
+	return errors.New("llog: sink not added")
+}
+
+// RemoveSink stops writing entries to a sink added with AddSink and
+// closes the sink.
+func RemoveSink(sink Sink) error {
+	globMutex.Lock()
+	var removed *sinkHandler
+	sinks := make([]*sinkHandler, 0, len(globSinks))
+	for _, s := range globSinks {
+		if s.sink == sink {
+			removed = s
+		} else {
+			sinks = append(sinks, s)
+		}
+	}
+	globSinks = sinks
+	globMutex.Unlock()
+
+	if removed == nil {
+		return errors.New("llog: sink not added")
+	}
+	removeHandler(&removed.handler)
+	return sink.Close()
+}
+
+// removeSinks removes and closes all sinks.
+func removeSinks() error {
+	globMutex.Lock()
+	sinks := globSinks
+	globMutex.Unlock()
+	var firstErr error
+	for _, s := range sinks {
+		if err := RemoveSink(s.sink); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,130 @@
+// Unit tests for sinks
+package llog
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"testing"
+)
+
+// testSink is a sink keeping the entries written
+type testSink struct {
+	mutex   sync.Mutex
+	entries []Entry
+	err     error // returned by Write
+	closed  bool
+}
+
+func (s *testSink) Write(e Entry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.entries = append(s.entries, e)
+	return s.err
+}
+
+func (s *testSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *testSink) isClosed() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.closed
+}
+
+func (s *testSink) messages() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var messages []string
+	for _, e := range s.entries {
+		messages = append(messages, e.Message)
+	}
+	return messages
+}
+
+func TestSink(t *testing.T) {
+	log.SetOutput(new(bytes.Buffer))
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	var reported error
+	SetErrorHandler(func(err error) { reported = err })
+	defer SetErrorHandler(nil)
+
+	sink := &testSink{}
+	Info("before")
+	AddSink(sink)
+	Info("first")
+	Debug("filtered")
+	sink.err = errors.New("sink failure")
+	Warn("second")
+	if reported == nil {
+		t.Fatalf("Sink error not reported")
+	}
+	if err := RemoveSink(sink); err != nil {
+		t.Fatalf("Unable to remove sink. Reason: %s", err)
+	}
+	Info("after")
+
+	if messages := sink.messages(); len(messages) != 2 || messages[0] != "first" || messages[1] != "second" {
+		t.Fatalf("Sink got wrong entries: %v", messages)
+	}
+	if !sink.closed {
+		t.Fatalf("Sink not closed when removed")
+	}
+	if err := RemoveSink(sink); err == nil {
+		t.Fatalf("Removing a sink twice shall give an error")
+	}
+
+	// Close shall close all sinks
+	sink = &testSink{}
+	AddSink(sink)
+	Close()
+	if !sink.closed || nbrOfHandlers() != 0 {
+		t.Fatalf("Sink not closed by Close")
+	}
+}
+
+func TestDestinationLevels(t *testing.T) {
+	skipInRelease(t)
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlDebug)
+	defer SetLevel(LvlInfo)
+	SetOutputLevel(LvlInfo)
+	defer SetOutputLevel(0)
+	sink := &testSink{}
+	AddSink(sink)
+	defer RemoveSink(sink)
+	if err := SetSinkLevel(sink, LvlWarn); err != nil {
+		t.Fatalf("Unable to set sink level. Reason: %s", err)
+	}
+	if err := SetSinkLevel(&testSink{}, LvlWarn); err == nil {
+		t.Fatal("No error for sink not added")
+	}
+
+	Trace("nowhere")
+	Debug("debug")
+	Info("info")
+	Warn("warn")
+	Audit("audit")
+
+	entries := readEntries(t, buffer)
+	if len(entries) != 3 || entries[0].Message != "info" || entries[2].Message != "audit" {
+		t.Fatalf("Output level not applied: %v", entries)
+	}
+	if messages := sink.messages(); len(messages) != 2 || messages[0] != "warn" || messages[1] != "audit" {
+		t.Fatalf("Sink level not applied: %v", messages)
+	}
+	SetSinkLevel(sink, 0)
+	Debug("debug to sink")
+	if messages := sink.messages(); len(messages) != 3 || buffer.Len() != 0 {
+		t.Fatalf("Sink level not reset: %v", messages)
+	}
+}
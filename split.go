@@ -0,0 +1,160 @@
+package llog
+
+import (
+	"errors"
+	"log"
+)
+
+// FileSplit selects the entries written to a file added with AddFile
+type FileSplit struct {
+	// MinLevel is the lowest level written, 0 for all levels
+	MinLevel Level
+	// MaxLevel is the highest level written. 0 writes all levels but
+	// audit, which is only written if MaxLevel is LvlAudit.
+	MaxLevel Level
+	// Workers are the worker tags, see WithWorker, of the entries
+	// written, for example the components of the application. Entries
+	// of all workers are written if empty.
+	Workers []string
+}
+
+// match returns true if the entry shall be written to the file.
+func (s *FileSplit) match(e *Entry) bool {
+	maxLevel := s.MaxLevel
+	if maxLevel == 0 {
+		maxLevel = LvlPanic
+	}
+	if e.Level < s.MinLevel || e.Level > maxLevel {
+		return false
+	}
+	if len(s.Workers) == 0 {
+		return true
+	}
+	for _, worker := range s.Workers {
+		if e.Worker == worker {
+			return true
+		}
+	}
+	return false
+}
+
+// splitFile is a file added with AddFile
+type splitFile struct {
+	handler entryHandler
+	file    *logFile
+	split   FileSplit
+}
+
+// globSplitFiles are the files added with AddFile
+var globSplitFiles []*splitFile
+
+// AddFile writes a selection of the entries to another log file, in
+// addition to the log file set with SetFile, for example the errors or
+// the entries of a component:
+//
+//	llog.SetFile("app.log", 10240)
+//	llog.AddFile("app-error.log", 1024, llog.FileSplit{MinLevel: llog.LvlError})
+//	llog.AddFile("db.log", 1024, llog.FileSplit{Workers: []string{"db"}})
+//	llog.SetAuditFile("audit.log", 1024)
+//
+// Each file is wrapped independently, with a backup when it is more than
+// maxSizeKB, and uses the options, such as the format, set when it is
+// added. The entries are written regardless of the level set with
+// SetOutputLevel, but not below the level set with SetLevel.
+func AddFile(fileName string, maxSizeKB int, split FileSplit) error {
+	file, err := openLogFile(fileName, maxSizeKB)
+	if err != nil {
+		return err
+	}
+	s := &splitFile{file: file, split: split}
+	s.split.Workers = append([]string(nil), split.Workers...)
+	s.handler.handle = s.handle
+
+	globMutex.Lock()
+	for _, other := range globSplitFiles {
+		if other.file.name == fileName {
+			globMutex.Unlock()
+			file.Close()
+			return errors.New("llog: file already added")
+		}
+	}
+	if file.format == FormatBinary {
+		file.startBinary()
+	}
+	if globHeader != nil || file.metadata {
+		file.setHeader(globHeader)
+	}
+	globSplitFiles = append(globSplitFiles, s)
+	globMutex.Unlock()
+	addHandler(&s.handler)
+	return nil
+}
+
+// RemoveFile stops writing entries to a file added with AddFile and
+// closes the file.
+func RemoveFile(fileName string) error {
+	globMutex.Lock()
+	var removed *splitFile
+	files := make([]*splitFile, 0, len(globSplitFiles))
+	for _, s := range globSplitFiles {
+		if s.file.name == fileName {
+			removed = s
+		} else {
+			files = append(files, s)
+		}
+	}
+	globSplitFiles = files
+	globMutex.Unlock()
+
+	if removed == nil {
+		return errors.New("llog: file not added")
+	}
+	removeHandler(&removed.handler)
+	return closeLogFile(removed.file)
+}
+
+// removeSplitFiles removes and closes all files added with AddFile.
+func removeSplitFiles() error {
+	globMutex.Lock()
+	files := globSplitFiles
+	globMutex.Unlock()
+	var firstErr error
+	for _, s := range files {
+		if err := RemoveFile(s.file.name); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// splitLogFiles returns the files added with AddFile.
+func splitLogFiles() []*logFile {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	files := make([]*logFile, 0, len(globSplitFiles))
+	for _, s := range globSplitFiles {
+		files = append(files, s.file)
+	}
+	return files
+}
+
+// handle writes the entry to the file if selected by the split.
+func (s *splitFile) handle(e *Entry) {
+	if !s.split.match(e) {
+		return
+	}
+	switch s.file.format {
+	case FormatBinary:
+		s.file.writeEntry(e, log.Flags())
+	default:
+		buf := getBuffer()
+		defer putBuffer(buf)
+		if s.file.format == FormatJSON {
+			*buf = appendJSON(*buf, e, log.Flags())
+		} else {
+			*buf = appendEntry(*buf, e, log.Prefix(), log.Flags())
+		}
+		writeOutput(s.file, *buf)
+	}
+	s.file.entryWritten(e.Level)
+}
@@ -0,0 +1,100 @@
+// Unit tests for split
+package llog
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fileMessages returns the messages of the entries in a log file.
+func fileMessages(t *testing.T, fileName string) []string {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("Unable to read %s. Reason: %s", fileName, err)
+	}
+	var messages []string
+	for _, e := range readEntries(t, bytes.NewBuffer(content)) {
+		messages = append(messages, e.Message)
+	}
+	return messages
+}
+
+func TestAddFile(t *testing.T) {
+	dir := t.TempDir()
+	appLog := filepath.Join(dir, "app.log")
+	errorLog := filepath.Join(dir, "app-error.log")
+	dbLog := filepath.Join(dir, "db.log")
+	SetLevel(LvlInfo)
+	if err := SetFile(appLog, 1024); err != nil {
+		t.Fatalf("Unable to set file. Reason: %s", err)
+	}
+	if err := AddFile(errorLog, 1024, FileSplit{MinLevel: LvlError}); err != nil {
+		t.Fatalf("Unable to add file. Reason: %s", err)
+	}
+	if err := AddFile(dbLog, 1024, FileSplit{Workers: []string{"db"}, MaxLevel: LvlAudit}); err != nil {
+		t.Fatalf("Unable to add file. Reason: %s", err)
+	}
+	if err := AddFile(dbLog, 1024, FileSplit{}); err == nil {
+		t.Fatalf("Adding a file twice shall give an error")
+	}
+
+	Info("info")
+	Error("error")
+	Audit("audit")
+	db := WithWorker("db")
+	db.Debug("below level")
+	db.Info("db info")
+	db.Error("db error")
+	db.Audit("db audit")
+	if err := RemoveFile(errorLog); err != nil {
+		t.Fatalf("Unable to remove file. Reason: %s", err)
+	}
+	Error("after remove")
+	if err := RemoveFile(errorLog); err == nil {
+		t.Fatalf("Removing a file twice shall give an error")
+	}
+	Close()
+
+	check := func(fileName string, want ...string) {
+		t.Helper()
+		got := fileMessages(t, fileName)
+		if len(got) != len(want) {
+			t.Fatalf("%s: got %q, want %q", fileName, got, want)
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("%s: got %q, want %q", fileName, got, want)
+			}
+		}
+	}
+	check(appLog, "info", "error", "audit", "db info", "db error", "db audit", "after remove")
+	check(errorLog, "error", "db error")
+	check(dbLog, "db info", "db error", "db audit")
+	if len(splitLogFiles()) != 0 {
+		t.Fatalf("Files not removed by Close")
+	}
+}
+
+func TestAddFileNoCaller(t *testing.T) {
+	log.SetOutput(new(bytes.Buffer))
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	splitLog := filepath.Join(t.TempDir(), "split.log")
+	if err := AddFile(splitLog, 1024, FileSplit{}); err != nil {
+		t.Fatalf("Unable to add file. Reason: %s", err)
+	}
+	WithCaller(NoCaller()).Info("no caller")
+	Info("caller")
+	RemoveFile(splitLog)
+
+	content, _ := os.ReadFile(splitLog)
+	lines := strings.Split(string(content), "\n")
+	if len(lines) != 3 || strings.Contains(lines[0], ":0:") || !strings.Contains(lines[0], "INFO - no caller") ||
+		!strings.Contains(lines[1], "split_test.go:") {
+		t.Fatalf("Caller not written as in the log:\n%s", content)
+	}
+}
@@ -0,0 +1,146 @@
+package llog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SQLite is a sink, see AddSink, writing entries into a table in a SQLite
+// database, so that the log can be queried with SQL. The oldest entries
+// are pruned by number of entries or by size, see MaxRows and MaxSizeKB.
+// llog doesn't depend on a SQLite driver, instead the application opens
+// the database with its driver. The table is created if it doesn't
+// exist:
+//
+//	CREATE TABLE log (
+//		id      INTEGER PRIMARY KEY AUTOINCREMENT,
+//		time    INTEGER NOT NULL, -- Unix time in milliseconds
+//		level   INTEGER NOT NULL, -- see Level
+//		caller  TEXT NOT NULL,    -- file.go:23
+//		message TEXT NOT NULL
+//	)
+//
+// For example, the errors the last hour:
+//
+//	SELECT * FROM log WHERE level >= 5 AND time > (strftime('%s','now') - 3600) * 1000
+type SQLite struct {
+	// DB is the database
+	DB *sql.DB
+	// Table is the name of the table. Default is "log".
+	Table string
+	// MaxRows is the max number of entries kept. The oldest entries are
+	// deleted when exceeded. 0 keeps all entries.
+	MaxRows int
+	// MaxSizeKB is the max size of the entries kept, counted as the
+	// length of the caller and the message plus 32 bytes per entry. The
+	// oldest entries are deleted when exceeded. 0 keeps all entries.
+	// Space of deleted entries is reused by SQLite, but the database file
+	// only shrinks if auto_vacuum is enabled.
+	MaxSizeKB int
+	// Batch configures the batching of entries. Each batch is written in
+	// one transaction.
+	Batch BatchOptions
+
+	once    sync.Once
+	batcher batcher
+	table   string // Table quoted as an identifier
+	created bool   // true when the table has been created
+}
+
+// Write queues the entry to be written.
+func (s *SQLite) Write(e Entry) error {
+	s.once.Do(s.start)
+	return s.batcher.add(e)
+}
+
+// Close writes the queued entries. The database is not closed.
+func (s *SQLite) Close() error {
+	s.once.Do(func() {})
+	s.batcher.close()
+	return nil
+}
+
+// start fills in defaults and starts the batcher.
+func (s *SQLite) start() {
+	if s.Table == "" {
+		s.Table = "log"
+	}
+	s.table = sqliteIdent(s.Table)
+	s.batcher.start(s.Batch, s.send)
+}
+
+// sqliteIdent quotes name as an SQL identifier.
+func sqliteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// sqliteRowSize is the size counted for each entry, besides the caller
+// and the message, see MaxSizeKB
+const sqliteRowSize = 32
+
+// send writes a batch in one transaction and deletes the oldest entries
+// if MaxRows or MaxSizeKB is exceeded.
+func (s *SQLite) send(batch []Entry) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if !s.created {
+		_, err := s.DB.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id      INTEGER PRIMARY KEY AUTOINCREMENT,
+	time    INTEGER NOT NULL,
+	level   INTEGER NOT NULL,
+	caller  TEXT NOT NULL,
+	message TEXT NOT NULL
+)`, s.table))
+		if err != nil {
+			return 0, err
+		}
+		s.created = true
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback() // No effect if committed
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf(
+		`INSERT INTO %s (time, level, caller, message) VALUES (?, ?, ?, ?)`, s.table))
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+	for _, e := range batch {
+		caller := filepath.Base(e.File) + ":" + strconv.Itoa(e.Line)
+		if _, err := stmt.ExecContext(ctx, e.Time.UnixMilli(), int(e.Level), caller, e.Message); err != nil {
+			return 0, err
+		}
+	}
+	if s.MaxRows > 0 {
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(
+			`DELETE FROM %[1]s WHERE id <= (SELECT MAX(id) FROM %[1]s) - ?`, s.table), s.MaxRows)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if s.MaxSizeKB > 0 {
+		// The newest entries are kept up to the size
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %[1]s WHERE id IN (
+	SELECT id FROM (
+		SELECT id, SUM(LENGTH(caller) + LENGTH(message) + %[2]d) OVER (ORDER BY id DESC) AS size
+		FROM %[1]s
+	) WHERE size > ?
+)`, s.table, sqliteRowSize), s.MaxSizeKB*1024)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(batch), nil
+}
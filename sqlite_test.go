@@ -0,0 +1,148 @@
+// Unit tests for the SQLite sink
+package llog
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testDriver is a database driver recording the executed statements,
+// since no SQLite driver is available in the tests
+type testDriver struct {
+	mutex      sync.Mutex
+	statements []string
+	queries    []string // the full statements
+	committed  int
+}
+
+// testDB is the driver registered as "llogtest", once since drivers
+// can't be registered again
+var testDB = &testDriver{}
+
+func init() {
+	sql.Register("llogtest", testDB)
+}
+
+// reset forgets the statements executed.
+func (d *testDriver) reset() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.statements = nil
+	d.queries = nil
+	d.committed = 0
+}
+
+func (d *testDriver) Open(name string) (driver.Conn, error) { return &testConn{d}, nil }
+
+func (d *testDriver) exec(query string, args []driver.Value) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.statements = append(d.statements, fmt.Sprintf("%s %v", strings.Fields(query)[0], args))
+	d.queries = append(d.queries, query)
+}
+
+type testConn struct{ d *testDriver }
+
+func (c *testConn) Prepare(query string) (driver.Stmt, error) { return &testStmt{c.d, query}, nil }
+func (c *testConn) Close() error                              { return nil }
+func (c *testConn) Begin() (driver.Tx, error)                 { return &testTx{c.d}, nil }
+
+type testStmt struct {
+	d     *testDriver
+	query string
+}
+
+func (s *testStmt) Close() error  { return nil }
+func (s *testStmt) NumInput() int { return -1 }
+func (s *testStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.exec(s.query, args)
+	return driver.RowsAffected(1), nil
+}
+func (s *testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+type testTx struct{ d *testDriver }
+
+func (t *testTx) Commit() error {
+	t.d.mutex.Lock()
+	defer t.d.mutex.Unlock()
+	t.d.committed++
+	return nil
+}
+func (t *testTx) Rollback() error { return nil }
+
+func TestSQLite(t *testing.T) {
+	d := testDB
+	d.reset()
+	db, _ := sql.Open("llogtest", "")
+	defer db.Close()
+	log.SetOutput(new(bytes.Buffer))
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+
+	sink := &SQLite{DB: db, MaxRows: 1000}
+	AddSink(sink)
+	Info("first")
+	Error("second")
+	_, line, _ := caller(0)
+	RemoveSink(sink)
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if len(d.statements) != 4 || d.committed != 1 {
+		t.Fatalf("Expected 4 statements in one transaction, got %v", d.statements)
+	}
+	if d.statements[0] != "CREATE []" || !strings.HasPrefix(d.statements[3], "DELETE [1000]") {
+		t.Fatalf("Wrong statements: %v", d.statements)
+	}
+	if !strings.HasSuffix(d.statements[2], fmt.Sprintf(" 5 sqlite_test.go:%d second]", line-1)) {
+		t.Fatalf("Wrong insert: %s", d.statements[2])
+	}
+}
+
+func TestSQLiteMaxSize(t *testing.T) {
+	d := testDB
+	d.reset()
+	db, _ := sql.Open("llogtest", "")
+	defer db.Close()
+	log.SetOutput(new(bytes.Buffer))
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+
+	sink := &SQLite{DB: db, MaxSizeKB: 64}
+	AddSink(sink)
+	Info("entry")
+	RemoveSink(sink)
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if len(d.statements) != 3 || d.statements[2] != "DELETE [65536]" {
+		t.Fatalf("Entries not pruned by size: %v", d.statements)
+	}
+}
+
+func TestSQLiteTable(t *testing.T) {
+	d := testDB
+	d.reset()
+	db, err := sql.Open("llogtest", "")
+	if err != nil {
+		t.Fatalf("Unable to open database. Reason: %s", err)
+	}
+	defer db.Close()
+	sink := &SQLite{DB: db, Table: `my"log\`}
+	sink.Write(Entry{Level: LvlInfo, Message: "entry"})
+	sink.Close()
+	if len(d.queries) != 2 || !strings.HasPrefix(d.queries[0], `CREATE TABLE IF NOT EXISTS "my""log\" (`) ||
+		!strings.HasPrefix(d.queries[1], `INSERT INTO "my""log\" (`) {
+		t.Fatalf("Table not quoted: %q", d.queries)
+	}
+}
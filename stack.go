@@ -0,0 +1,94 @@
+package llog
+
+import (
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// globStackOnFirstError is true if a stack trace is written with the
+// first occurrence of each error, see SetStackOnFirstError
+var globStackOnFirstError bool
+
+// maxSeenErrors is the max number of errors remembered. No stack traces
+// are written for new errors when reached.
+const maxSeenErrors = 1000
+
+// globSeenErrors are the keys of the errors written with a stack trace
+var globSeenErrors struct {
+	mutex sync.Mutex
+	keys  map[string]bool
+}
+
+// SetStackOnFirstError writes the stack trace of the caller after the
+// message of entries on error and panic level, but only the first time
+// each error is written, which gives debuggability without the cost and
+// noise of stack traces on every repeated error. An error is identified
+// by its event code, see WithEvent, or if none by its format string, so
+// that the same error with different values is only written once with a
+// stack trace. The stack trace is written as a multi line message:
+//
+//	2009/01/23 01:23:23 file.go:23: ERROR - connection lost
+//	main.connect(...)
+//		/src/main.go:23
+//	main.main()
+//		/src/main.go:12
+//
+// At most 1000 errors are remembered. Disabling forgets the errors.
+func SetStackOnFirstError(enable bool) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globStackOnFirstError = enable
+	updateOutput()
+	globSeenErrors.mutex.Lock()
+	globSeenErrors.keys = nil
+	globSeenErrors.mutex.Unlock()
+}
+
+// firstError returns true the first time it is called with key, unless
+// maxSeenErrors errors have been seen.
+func firstError(key string) bool {
+	globSeenErrors.mutex.Lock()
+	defer globSeenErrors.mutex.Unlock()
+	if globSeenErrors.keys[key] || len(globSeenErrors.keys) >= maxSeenErrors {
+		return false
+	}
+	if globSeenErrors.keys == nil {
+		globSeenErrors.keys = make(map[string]bool)
+	}
+	globSeenErrors.keys[key] = true
+	return true
+}
+
+// errorKey returns the key identifying an error with the message msg,
+// see SetStackOnFirstError.
+func (l *Logger) errorKey(kind msgKind, format string, msg []byte) string {
+	switch {
+	case l.event != "":
+		return "event:" + string(l.event)
+	case kind == msgPrintf:
+		return format
+	default:
+		return string(msg)
+	}
+}
+
+// appendStack appends the stack trace, each line preceded by a newline,
+// skipping frames as runtime.Caller(skip).
+func appendStack(b []byte, skip int) []byte {
+	pcs := make([]uintptr, 64)
+	pcs = pcs[:runtime.Callers(skip+2, pcs)]
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		b = append(b, '\n')
+		b = append(b, frame.Function...)
+		b = append(b, "(...)\n\t"...)
+		b = append(b, frame.File...)
+		b = append(b, ':')
+		b = strconv.AppendInt(b, int64(frame.Line), 10)
+		if !more {
+			return b
+		}
+	}
+}
@@ -0,0 +1,40 @@
+// Unit tests for stack
+package llog
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestStackOnFirstError(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	SetStackOnFirstError(true)
+	defer SetStackOnFirstError(false)
+
+	for i := 0; i < 2; i++ {
+		Error("connection %d lost", i)
+		WithEvent(evTest).Errorln("event", i)
+		Warn("warning")
+	}
+
+	entries := readEntries(t, buffer)
+	if len(entries) != 6 {
+		t.Fatalf("Wrong number of entries: %v", entries)
+	}
+	for i, e := range entries {
+		withStack := strings.Contains(e.Message, "\n")
+		if withStack != (i < 2) {
+			t.Fatalf("Stack trace not written on first error only: %d %q", i, e.Message)
+		}
+	}
+	if !strings.HasPrefix(entries[0].Message, "connection 0 lost\ngithub.com/midstar/llog.TestStackOnFirstError(...)\n\t") ||
+		!strings.Contains(entries[0].Message, "stack_test.go:21") || entries[1].Event() != evTest {
+		t.Fatalf("Stack trace not correct: %q", entries[0].Message)
+	}
+}
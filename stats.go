@@ -0,0 +1,30 @@
+package llog
+
+import "sync/atomic"
+
+// Statistics are counters of events in llog since start.
+type Statistics struct {
+	// Dropped is the number of entries that have been dropped
+	Dropped uint64
+	// WriteErrors is the number of failed writes to log files
+	WriteErrors uint64
+	// Blocked is the number of writes to sinks that waited for room in
+	// a full queue, see Block
+	Blocked uint64
+}
+
+// globStats counts events for Stats
+var globStats struct {
+	dropped     atomic.Uint64
+	writeErrors atomic.Uint64
+	blocked     atomic.Uint64
+}
+
+// Stats returns the counters of events in llog since start.
+func Stats() Statistics {
+	return Statistics{
+		Dropped:     globStats.dropped.Load(),
+		WriteErrors: globStats.writeErrors.Load(),
+		Blocked:     globStats.blocked.Load(),
+	}
+}
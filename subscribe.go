@@ -0,0 +1,51 @@
+package llog
+
+import "sync"
+
+// subscriberBufferSize is the number of entries buffered for each
+// subscriber
+const subscriberBufferSize = 256
+
+// subscriber receives entries on a channel
+type subscriber struct {
+	handler entryHandler
+	ch      chan Entry
+	closed  bool
+	mutex   sync.Mutex // protects closed and sending on ch
+}
+
+// Subscribe returns a channel receiving each entry written to the log from
+// now on, for example to show the log live in an admin UI. Entries are
+// dropped if the subscriber doesn't keep up with the logging, the channel
+// buffers 256 entries. Call cancel to unsubscribe, which closes the
+// channel.
+func Subscribe() (entries <-chan Entry, cancel func()) {
+	s := &subscriber{ch: make(chan Entry, subscriberBufferSize)}
+	s.handler.handle = s.handle
+	addHandler(&s.handler)
+	return s.ch, s.cancel
+}
+
+// handle is called with each entry written to the log.
+func (s *subscriber) handle(e *Entry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.ch <- *e:
+	default: // Subscriber doesn't keep up, drop entry
+	}
+}
+
+// cancel unsubscribes and closes the channel.
+func (s *subscriber) cancel() {
+	removeHandler(&s.handler)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
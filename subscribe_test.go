@@ -0,0 +1,46 @@
+// Unit tests for subscriptions
+package llog
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSubscribe(t *testing.T) {
+	log.SetOutput(new(bytes.Buffer))
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	entries, cancel := Subscribe()
+
+	Debug("not logged")
+	Warn("a warning %d", 1)
+	e := <-entries
+	if e.Level != LvlWarn || e.Message != "a warning 1" {
+		t.Fatalf("Unexpected entry: %v", e)
+	}
+	if filepath.Base(e.File) != "subscribe_test.go" || e.Line == 0 {
+		t.Fatalf("Caller not correct: %s:%d", e.File, e.Line)
+	}
+
+	// Slow subscriber shall not block logging
+	for i := 0; i < 2*subscriberBufferSize; i++ {
+		Info("entry %d", i)
+	}
+	if len(entries) != subscriberBufferSize {
+		t.Fatalf("Expected full buffer, got %d", len(entries))
+	}
+
+	cancel()
+	Info("after cancel")
+	n := 0
+	for range entries {
+		n++
+	}
+	if n != subscriberBufferSize {
+		t.Fatalf("Entries received after cancel")
+	}
+	cancel() // Shall be safe to call twice
+}
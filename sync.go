@@ -0,0 +1,28 @@
+package llog
+
+import "time"
+
+// SyncPolicy decides when log files are committed to stable storage
+// (synced). Syncing often makes sure entries survive a crash, at the
+// cost of throughput. The conditions are combined, i.e. a sync is made
+// when any of them is fulfilled. The zero value never syncs, leaving it
+// to the operating system.
+type SyncPolicy struct {
+	// Entries syncs after this number of entries, 0 to disable. Not
+	// used for buffered files, see SetBuffering.
+	Entries int
+	// Interval syncs at most this time after an entry was written,
+	// 0 to disable
+	Interval time.Duration
+	// MinLevel syncs directly after each entry of this level or above,
+	// 0 to disable
+	MinLevel Level
+}
+
+// SetSyncPolicy sets when log files opened after this call are synced.
+// Default is to sync every 20th entry.
+func SetSyncPolicy(policy SyncPolicy) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globFileOptions.sync = policy
+}
@@ -0,0 +1,60 @@
+// Unit tests for the sync policy
+package llog
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSyncPolicy(t *testing.T) {
+	logFileName := "synclog.txt"
+	defer SetSyncPolicy(SyncPolicy{Entries: 20})
+
+	SetSyncPolicy(SyncPolicy{Entries: 3, MinLevel: LvlWarn})
+	file, err := openLogFile(logFileName, 100)
+	if err != nil {
+		t.Fatalf("Unable to open log file. Reason: %s", err)
+	}
+	file.entryWritten(LvlInfo)
+	file.entryWritten(LvlInfo)
+	if file.unsynced != 2 {
+		t.Fatalf("Shall not sync before 3 entries")
+	}
+	file.entryWritten(LvlInfo)
+	if file.unsynced != 0 {
+		t.Fatalf("Shall sync after 3 entries")
+	}
+	file.entryWritten(LvlInfo)
+	file.entryWritten(LvlWarn)
+	if file.unsynced != 0 {
+		t.Fatalf("Shall sync after warning")
+	}
+	file.Close()
+
+	SetSyncPolicy(SyncPolicy{Interval: 10 * time.Millisecond})
+	file, err = openLogFile(logFileName, 100)
+	if err != nil {
+		t.Fatalf("Unable to open log file. Reason: %s", err)
+	}
+	for i := 0; i < 100; i++ {
+		file.entryWritten(LvlError)
+	}
+	file.mutex.Lock()
+	unsynced := file.unsynced
+	file.mutex.Unlock()
+	if unsynced != 100 {
+		t.Fatalf("Shall not sync before interval")
+	}
+	time.Sleep(50 * time.Millisecond)
+	file.mutex.Lock()
+	unsynced = file.unsynced
+	file.mutex.Unlock()
+	if unsynced != 0 {
+		t.Fatalf("Shall sync after interval")
+	}
+	file.Close()
+
+	// Cleanup
+	os.Remove(logFileName)
+}
@@ -0,0 +1,79 @@
+package llog
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// tailKeepAlive is the interval of keep alive comments sent by the
+// tail handler to keep idle connections open
+const tailKeepAlive = 30 * time.Second
+
+// ParseLevel returns the level with the name, as written in the log, for
+// example "WARN". The name is not case sensitive.
+func ParseLevel(name string) (Level, error) {
+	for level, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("llog: unknown level %q", name)
+}
+
+// TailHandler returns a HTTP handler that streams the log entries, as
+// they are written, using Server-Sent Events (SSE). Each entry is sent as
+// an event with the entry, on the default log format, as data. The query
+// parameter "level" sets the lowest level streamed, for example
+// "/log?level=warn".
+//
+// In a browser the log can be watched using EventSource:
+//
+//	new EventSource("/log").onmessage = (e) => console.log(e.data)
+func TailHandler() http.Handler {
+	return http.HandlerFunc(serveTail)
+}
+
+func serveTail(w http.ResponseWriter, r *http.Request) {
+	minLevel := LvlTrace
+	if name := r.URL.Query().Get("level"); name != "" {
+		var err error
+		if minLevel, err = ParseLevel(name); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	entries, cancel := Subscribe()
+	defer cancel()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(tailKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case e := <-entries:
+			if e.Level < minLevel {
+				continue
+			}
+			for _, line := range strings.Split(e.String(), "\n") {
+				fmt.Fprintf(w, "data: %s\n", line)
+			}
+			fmt.Fprint(w, "\n")
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep alive\n\n")
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,71 @@
+// Unit tests for the tail handler
+package llog
+
+import (
+	"bufio"
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("warn")
+	if err != nil || level != LvlWarn {
+		t.Fatalf("Unable to parse level: %v %s", level, err)
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Fatalf("Unknown level shall give an error")
+	}
+}
+
+func TestTailHandler(t *testing.T) {
+	log.SetOutput(new(bytes.Buffer))
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	server := httptest.NewServer(TailHandler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?level=warn")
+	if err != nil {
+		t.Fatalf("Unable to connect. Reason: %s", err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Wrong content type: %s", resp.Header.Get("Content-Type"))
+	}
+	// Wait for the handler to subscribe
+	for i := 0; i < 100 && nbrOfHandlers() == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	Info("filtered")
+	Warn("streamed\nsecond line")
+
+	reader := bufio.NewReader(resp.Body)
+	line, _ := reader.ReadString('\n')
+	if !strings.HasPrefix(line, "data: ") || !strings.Contains(line, "WARN - streamed") {
+		t.Fatalf("Unexpected event: %q", line)
+	}
+	line, _ = reader.ReadString('\n')
+	if line != "data: second line\n" {
+		t.Fatalf("Unexpected event: %q", line)
+	}
+}
+
+func TestTailHandlerInvalidLevel(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	TailHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/?level=verbose", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("Invalid level shall give bad request, got %d", recorder.Code)
+	}
+}
+
+func nbrOfHandlers() int {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	return len(globHandlers)
+}
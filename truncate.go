@@ -0,0 +1,42 @@
+package llog
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// globMaxMessageLength is the max length of messages or 0 if unlimited
+var globMaxMessageLength int
+
+// SetMaxMessageLength truncates messages longer than maxLength bytes,
+// for example when a whole response body is logged by mistake, which
+// protects log file wrapping, sinks and log parsers from huge entries.
+// A truncated message is marked with the number of bytes removed:
+//
+//	2009/01/23 01:23:23 file.go:23: INFO - response: {"items":[{"id":1…[truncated 18234 bytes]
+//
+// Messages are truncated at a character boundary. A maxLength of 0
+// disables truncation (default).
+func SetMaxMessageLength(maxLength int) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globMaxMessageLength = maxLength
+	updateOutput()
+}
+
+// truncateMessage truncates the message in b, starting at start, to
+// maxLength bytes and appends the truncation mark, if longer.
+func truncateMessage(b []byte, start, maxLength int) []byte {
+	removed := len(b) - start - maxLength
+	if maxLength <= 0 || removed <= 0 {
+		return b
+	}
+	end := start + maxLength
+	for end > start && !utf8.RuneStart(b[end]) {
+		end--
+	}
+	removed = len(b) - end
+	b = append(b[:end], "…[truncated "...)
+	b = strconv.AppendInt(b, int64(removed), 10)
+	return append(b, " bytes]"...)
+}
@@ -0,0 +1,32 @@
+// Unit tests for truncate
+package llog
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMaxMessageLength(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	SetMaxMessageLength(10)
+	defer SetMaxMessageLength(0)
+
+	Info("short")
+	Info("%s", strings.Repeat("x", 100))
+	Info("åäöåäöåäö") // 18 bytes, cut within a character
+	WriteBatch([]Entry{{Level: LvlWarn, Message: strings.Repeat("y", 20)}})
+
+	entries := readEntries(t, buffer)
+	if len(entries) != 4 || entries[0].Message != "short" ||
+		entries[1].Message != "xxxxxxxxxx…[truncated 90 bytes]" ||
+		entries[2].Message != "åäöåä…[truncated 8 bytes]" ||
+		entries[3].Message != "yyyyyyyyyy…[truncated 10 bytes]" {
+		t.Fatalf("Messages not truncated: %q", entries)
+	}
+}
@@ -0,0 +1,75 @@
+//go:build !llog_release
+
+package llog
+
+// The trace and debug level functions are no-ops when built with the
+// llog_release build tag, see verbose_release.go.
+
+// Release is true when built with the llog_release build tag, so that
+// expensive trace and debug instrumentation can be removed by the
+// compiler:
+//
+//	if !llog.Release {
+//		llog.Debug("State: %s", dumpState())
+//	}
+const Release = false
+
+// Trace writes a log on trace level
+func Trace(format string, v ...interface{}) {
+	globLogger.loglevel(LvlTrace, msgPrintf, format, v)
+}
+
+// Debug writes a log on debug level
+func Debug(format string, v ...interface{}) {
+	globLogger.loglevel(LvlDebug, msgPrintf, format, v)
+}
+
+// Trace writes a log on trace level
+func (l *Logger) Trace(format string, v ...interface{}) {
+	l.loglevel(LvlTrace, msgPrintf, format, v)
+}
+
+// Debug writes a log on debug level
+func (l *Logger) Debug(format string, v ...interface{}) {
+	l.loglevel(LvlDebug, msgPrintf, format, v)
+}
+
+// Traceln writes a log on trace level, formatted as fmt.Sprintln
+func Traceln(v ...interface{}) {
+	globLogger.loglevel(LvlTrace, msgPrintln, "", v)
+}
+
+// TraceMsg writes a log on trace level, with msg as is
+func TraceMsg(msg string) {
+	globLogger.loglevel(LvlTrace, msgPlain, msg, nil)
+}
+
+// Debugln writes a log on debug level, formatted as fmt.Sprintln
+func Debugln(v ...interface{}) {
+	globLogger.loglevel(LvlDebug, msgPrintln, "", v)
+}
+
+// DebugMsg writes a log on debug level, with msg as is
+func DebugMsg(msg string) {
+	globLogger.loglevel(LvlDebug, msgPlain, msg, nil)
+}
+
+// Traceln writes a log on trace level, formatted as fmt.Sprintln
+func (l *Logger) Traceln(v ...interface{}) {
+	l.loglevel(LvlTrace, msgPrintln, "", v)
+}
+
+// TraceMsg writes a log on trace level, with msg as is
+func (l *Logger) TraceMsg(msg string) {
+	l.loglevel(LvlTrace, msgPlain, msg, nil)
+}
+
+// Debugln writes a log on debug level, formatted as fmt.Sprintln
+func (l *Logger) Debugln(v ...interface{}) {
+	l.loglevel(LvlDebug, msgPrintln, "", v)
+}
+
+// DebugMsg writes a log on debug level, with msg as is
+func (l *Logger) DebugMsg(msg string) {
+	l.loglevel(LvlDebug, msgPlain, msg, nil)
+}
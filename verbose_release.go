@@ -0,0 +1,51 @@
+//go:build llog_release
+
+package llog
+
+// The trace and debug level functions are empty when built with the
+// llog_release build tag, so that the compiler inlines them to nothing:
+//
+//	go build -tags llog_release
+//
+// The arguments are still evaluated, as for any function call, so calls
+// with expensive arguments shall be guarded by Release. Build without the
+// tag for the ordinary functions in verbose.go.
+
+// Release is true when built with the llog_release build tag
+const Release = true
+
+// Trace is a no-op in release builds
+func Trace(format string, v ...interface{}) {}
+
+// Debug is a no-op in release builds
+func Debug(format string, v ...interface{}) {}
+
+// Trace is a no-op in release builds
+func (l *Logger) Trace(format string, v ...interface{}) {}
+
+// Debug is a no-op in release builds
+func (l *Logger) Debug(format string, v ...interface{}) {}
+
+// Traceln is a no-op in release builds
+func Traceln(v ...interface{}) {}
+
+// TraceMsg is a no-op in release builds
+func TraceMsg(msg string) {}
+
+// Debugln is a no-op in release builds
+func Debugln(v ...interface{}) {}
+
+// DebugMsg is a no-op in release builds
+func DebugMsg(msg string) {}
+
+// Traceln is a no-op in release builds
+func (l *Logger) Traceln(v ...interface{}) {}
+
+// TraceMsg is a no-op in release builds
+func (l *Logger) TraceMsg(msg string) {}
+
+// Debugln is a no-op in release builds
+func (l *Logger) Debugln(v ...interface{}) {}
+
+// DebugMsg is a no-op in release builds
+func (l *Logger) DebugMsg(msg string) {}
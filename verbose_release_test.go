@@ -0,0 +1,38 @@
+//go:build llog_release
+
+// Unit tests for the release build, run with:
+//
+//	go test -tags llog_release
+package llog
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestReleaseBuild(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlTrace)
+	defer SetLevel(LvlInfo)
+
+	Trace("trace")
+	Debug("debug")
+	Traceln("trace")
+	DebugMsg("debug")
+	WithWorker("w").Debug("debug")
+	Info("info")
+	if entries := readEntries(t, buffer); !Release || len(entries) != 1 || entries[0].Message != "info" {
+		t.Fatalf("Trace and debug not removed: %q", entries)
+	}
+}
+
+func BenchmarkReleaseDebug(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Debug("value %d", i)
+	}
+}
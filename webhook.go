@@ -0,0 +1,184 @@
+package llog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Webhook posts entries of a minimum level, together with the entries
+// written just before, to a webhook URL. The default payload is
+// compatible with Slack incoming webhooks. Use Payload for other
+// services, such as PagerDuty.
+type Webhook struct {
+	// URL to post to
+	URL string
+	// MinLevel is the lowest level posted. Default is LvlPanic.
+	MinLevel Level
+	// MinInterval is the minimum time between two posts. Entries within
+	// this time are not posted, but counted in the next post. Default is
+	// one minute, so that an application in a crash loop or an error
+	// storm doesn't spam the webhook. A negative interval posts all
+	// entries.
+	MinInterval time.Duration
+	// StateFile is the name of a file used to remember the time of the
+	// last post between runs, so that an application in a crash loop
+	// doesn't spam the webhook. Empty if not used.
+	StateFile string
+	// Context is the number of entries written before the posted entry
+	// that are included. Default is 10.
+	Context int
+	// Payload creates the body that is posted. e is the entry to post,
+	// context the entries written before it and suppressed the number of
+	// entries not posted due to MinInterval since last post. Default is
+	// a Slack compatible payload.
+	Payload func(e Entry, context []Entry, suppressed int) ([]byte, error)
+	// Client used to post. Default is a client with 10 seconds timeout.
+	Client *http.Client
+
+	handler    entryHandler
+	mutex      sync.Mutex // protects all below
+	recent     *entryRing
+	lastPost   time.Time
+	suppressed int
+	posting    chan struct{} // limits the posts in the background
+}
+
+// maxWebhookPosts is the max number of posts in the background. Entries
+// are not posted, but counted in the next post, when reached.
+const maxWebhookPosts = 4
+
+// globWebhook is the webhook or nil if none
+var globWebhook *Webhook
+
+// SetWebhook posts entries to the webhook, see Webhook. Entries of
+// panic level are posted before panic() is called. Other entries are
+// posted in the background. Audit entries are never posted. A nil
+// webhook stops posting.
+//
+// Errors when posting are reported to the error handler, see
+// SetErrorHandler.
+func SetWebhook(webhook *Webhook) {
+	globMutex.Lock()
+	old := globWebhook
+	globWebhook = webhook
+	globMutex.Unlock()
+
+	if old != nil {
+		removeHandler(&old.handler)
+	}
+	if webhook == nil {
+		return
+	}
+	if webhook.MinLevel == 0 {
+		webhook.MinLevel = LvlPanic
+	}
+	if webhook.MinInterval == 0 {
+		webhook.MinInterval = time.Minute
+	}
+	if webhook.Context == 0 {
+		webhook.Context = 10
+	}
+	if webhook.Payload == nil {
+		webhook.Payload = slackPayload
+	}
+	if webhook.Client == nil {
+		webhook.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	webhook.recent = newEntryRing(webhook.Context)
+	webhook.posting = make(chan struct{}, maxWebhookPosts)
+	webhook.handler.handle = webhook.handle
+	addHandler(&webhook.handler)
+}
+
+// handle is called with each entry written to the log.
+func (w *Webhook) handle(e *Entry) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if e.Level < w.MinLevel || e.Level == LvlAudit {
+		w.recent.add(*e)
+		return
+	}
+	if !w.rateAllowed() {
+		w.suppressed++
+		w.recent.add(*e)
+		return
+	}
+	payload, err := w.Payload(*e, w.recent.recent(), w.suppressed)
+	w.recent.add(*e)
+	w.suppressed = 0
+	if err != nil {
+		reportError(fmt.Errorf("llog: unable to create webhook payload: %w", err))
+		return
+	}
+	if e.Level >= LvlPanic {
+		// Application is about to panic, so post before returning
+		w.post(payload)
+		return
+	}
+	select {
+	case w.posting <- struct{}{}:
+		go func() {
+			defer func() { <-w.posting }()
+			w.post(payload)
+		}()
+	default:
+		w.suppressed++
+	}
+}
+
+// rateAllowed returns true if a post is allowed with respect to
+// MinInterval. Mutex must be held.
+func (w *Webhook) rateAllowed() bool {
+	t := now()
+	lastPost := w.lastPost
+	if w.StateFile != "" {
+		if info, err := os.Stat(w.StateFile); err == nil && info.ModTime().After(lastPost) {
+			lastPost = info.ModTime()
+		}
+	}
+	if !lastPost.IsZero() && t.Sub(lastPost) < w.MinInterval {
+		return false
+	}
+	w.lastPost = t
+	if w.StateFile != "" {
+		if err := os.WriteFile(w.StateFile, nil, 0666); err != nil {
+			reportError(fmt.Errorf("llog: unable to write webhook state: %w", err))
+		}
+	}
+	return true
+}
+
+// post posts the payload to the webhook.
+func (w *Webhook) post(payload []byte) {
+	resp, err := w.Client.Post(w.URL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		reportError(fmt.Errorf("llog: unable to post to webhook: %w", err))
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		reportError(fmt.Errorf("llog: unable to post to webhook: %s", resp.Status))
+	}
+}
+
+// slackPayload creates a Slack compatible payload.
+func slackPayload(e Entry, context []Entry, suppressed int) ([]byte, error) {
+	host, _ := os.Hostname()
+	var text strings.Builder
+	fmt.Fprintf(&text, "*%s* on %s: %s", e.Level, host, e.Message)
+	if suppressed > 0 {
+		fmt.Fprintf(&text, "\n(%d entries suppressed since last notification)", suppressed)
+	}
+	text.WriteString("\n```\n")
+	for _, c := range context {
+		text.WriteString(c.String() + "\n")
+	}
+	text.WriteString(e.String() + "\n```")
+	return json.Marshal(map[string]string{"text": text.String()})
+}
@@ -0,0 +1,124 @@
+// Unit tests for the webhook notifier
+package llog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhook(t *testing.T) {
+	posts := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]string
+		json.Unmarshal(body, &payload)
+		posts <- payload["text"]
+	}))
+	defer server.Close()
+	SetWebhook(&Webhook{URL: server.URL, MinLevel: LvlError, MinInterval: time.Hour})
+	defer SetWebhook(nil)
+	SetLevel(LvlInfo)
+	log.SetOutput(new(bytes.Buffer))
+	defer log.SetOutput(os.Stderr)
+
+	Info("some context")
+	Error("first error")
+	Error("second error") // Suppressed
+	var text string
+	select {
+	case text = <-posts:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Nothing posted")
+	}
+	if !strings.Contains(text, "ERROR") || !strings.Contains(text, "first error") {
+		t.Fatalf("Error entry not posted: %s", text)
+	}
+	if !strings.Contains(text, "INFO - some context") {
+		t.Fatalf("Context not posted: %s", text)
+	}
+	if !strings.Contains(text, "webhook_test.go") {
+		t.Fatalf("File name not posted: %s", text)
+	}
+	select {
+	case text = <-posts:
+		t.Fatalf("Second error shall be suppressed: %s", text)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWebhookPanic(t *testing.T) {
+	var posted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		posted = string(body)
+	}))
+	defer server.Close()
+	stateFile := "webhookstate.txt"
+	os.Remove(stateFile)
+	SetWebhook(&Webhook{URL: server.URL, MinInterval: time.Hour, StateFile: stateFile})
+	defer SetWebhook(nil)
+	log.SetOutput(new(bytes.Buffer))
+	defer log.SetOutput(os.Stderr)
+
+	func() {
+		defer func() { recover() }()
+		Panic("crash %d", 1)
+	}()
+	// Posted synchronously before panic
+	if !strings.Contains(posted, "crash 1") {
+		t.Fatalf("Panic not posted: %s", posted)
+	}
+
+	// A restarted application shall respect the interval
+	SetWebhook(&Webhook{URL: server.URL, MinInterval: time.Hour, StateFile: stateFile})
+	posted = ""
+	func() {
+		defer func() { recover() }()
+		Panic("crash %d", 2)
+	}()
+	if posted != "" {
+		t.Fatalf("Second panic shall be suppressed: %s", posted)
+	}
+
+	// Cleanup
+	os.Remove(stateFile)
+}
+
+func TestWebhookDefaults(t *testing.T) {
+	posts := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		posts <- string(body)
+	}))
+	defer server.Close()
+	SetWebhook(&Webhook{URL: server.URL, MinLevel: LvlError})
+	defer SetWebhook(nil)
+	SetLevel(LvlInfo)
+	log.SetOutput(new(bytes.Buffer))
+	defer log.SetOutput(os.Stderr)
+
+	Audit("user joe logged in") // Never posted
+	Error("first error")
+	Error("second error") // Rate limited by default
+	select {
+	case text := <-posts:
+		if !strings.Contains(text, "first error") {
+			t.Fatalf("Wrong entry posted: %s", text)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Nothing posted")
+	}
+	select {
+	case text := <-posts:
+		t.Fatalf("Only one entry shall be posted: %s", text)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
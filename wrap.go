@@ -0,0 +1,55 @@
+package llog
+
+import "time"
+
+// WrapMode is the strategy used to wrap log files
+type WrapMode int
+
+const (
+	// WrapRename renames the log file to the backup file and creates a
+	// new log file (default)
+	WrapRename WrapMode = iota
+	// WrapCopyTruncate copies the log file to the backup file and then
+	// truncates the log file. The log file is never replaced, which
+	// is required by tools following the log file, such as "tail -f", and
+	// by processes that have inherited the open log file. Entries written
+	// by other processes during the copy might be lost.
+	WrapCopyTruncate
+)
+
+// SetWrapMode sets the strategy used to wrap log files opened after this
+// call.
+func SetWrapMode(mode WrapMode) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globFileOptions.wrapMode = mode
+}
+
+// SetWrapOnOpen wraps log files that are not empty when opened, i.e.
+// when SetFile or SetAuditFile is called, which gives each run of the
+// application a fresh log file. The log file from the previous run is
+// kept as backup.
+func SetWrapOnOpen(enable bool) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globFileOptions.wrapOnOpen = enable
+}
+
+// SetWrapInterval wraps log files opened after this call when they have
+// been written for interval, in addition to when they exceed their max
+// size, for example to start a new log file each day. An interval of 0
+// disables time based wrapping (default).
+//
+// The interval is measured from when the log file was opened or last
+// wrapped, not aligned to wall clock boundaries such as midnight, and
+// the file is wrapped after the first entry written when the interval
+// has passed. The interval is measured with the monotonic clock, so
+// wall clock jumps, for example when a device that booted with a 1970
+// clock gets the time from NTP, or DST changes, neither wrap the file
+// early nor late. A clock set with SetClock without monotonic readings
+// is used as is.
+func SetWrapInterval(interval time.Duration) {
+	globMutex.Lock()
+	defer globMutex.Unlock()
+	globFileOptions.wrapInterval = interval
+}
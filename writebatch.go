@@ -0,0 +1,105 @@
+package llog
+
+import "log"
+
+// batchWriteSize is the size of formatted entries written in one write
+// by WriteBatch, so that log files are wrapped between the writes
+const batchWriteSize = 32 * 1024
+
+// WriteBatch writes entries, for example collected while offline, to the
+// log with few writes. The entries are written with their own time, file
+// and line; a zero time is replaced with the current time. As for the
+// log functions, entries below the level set are skipped, audit entries
+// are written to the audit file, if any, entries are written to stdout
+// or stderr in dual stream mode and sequence numbers replace Seq if
+// enabled.
+func WriteBatch(entries []Entry) {
+	config := globOutput.Load()
+	if config.stopped {
+		return
+	}
+	writer, prefix, flags := log.Writer(), log.Prefix(), log.Flags()
+	logFile := config.file
+	dualStream := config.dualStream && logFile == nil
+	binaryFile := logFile != nil && writer == logFile && logFile.binary != nil
+	outFormat := config.format
+	if logFile != nil && writer == logFile {
+		outFormat = logFile.format
+	}
+	level := levelSet()
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	n, maxLevel := 0, Level(0)
+	write := func() {
+		if n == 0 {
+			return
+		}
+		writeOutput(writer, *buf)
+		if logFile != nil {
+			logFile.entriesWritten(n, maxLevel)
+		}
+		*buf = (*buf)[:0]
+		n, maxLevel = 0, 0
+	}
+
+	now := config.clock()
+	for i := range entries {
+		e := entries[i]
+		toAuditFile := e.Level == LvlAudit && config.auditFile != nil
+		if e.Level < level && e.Level != LvlAudit {
+			continue
+		}
+		if e.Time.IsZero() {
+			e.Time = now
+		}
+		if e.File == "" {
+			e.File = "???"
+		}
+		if config.sanitize {
+			e.Message = sanitizeString(e.Message)
+			e.Worker = sanitizeString(e.Worker)
+			e.Fields = sanitizeFields(e.Fields)
+		}
+		if config.maxLength > 0 && len(e.Message) > config.maxLength {
+			e.Message = string(truncateMessage([]byte(e.Message), 0, config.maxLength))
+		}
+		if !runHooks(config.hooks, &e) {
+			continue
+		}
+		toOutput := e.Level >= config.outputLevel || e.Level == LvlAudit
+		if config.sequence && toOutput {
+			e.Seq = nextSeq(toAuditFile)
+		}
+		if isError(e.Level) && globFirstError.Load() == nil {
+			rememberError(e)
+		}
+		switch {
+		case !toOutput:
+			// Only written to the handlers, see SetOutputLevel
+		case toAuditFile:
+			// Each audit entry is written by itself, since it might be sealed
+			b := appendEntry(nil, &e, config.auditLogger.Prefix(), config.auditLogger.Flags())
+			writeOutput(config.auditFile, b)
+			config.auditFile.entryWritten(e.Level)
+		case binaryFile:
+			logFile.writeEntry(&e, flags)
+			logFile.entryWritten(e.Level)
+		default:
+			if dualStream && consoleWriter(e.Level) != writer {
+				write()
+				writer = consoleWriter(e.Level)
+			}
+			*buf = formatEntry(*buf, &e, writer, prefix, flags, outFormat, config)
+			n++
+			maxLevel = max(maxLevel, e.Level)
+			if len(*buf) >= batchWriteSize {
+				write()
+			}
+		}
+		for _, h := range config.handlers {
+			h.handle(&e)
+		}
+	}
+	write()
+}
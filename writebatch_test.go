@@ -0,0 +1,56 @@
+// Unit tests for WriteBatch
+package llog
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countingWriter counts the writes
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestWriteBatch(t *testing.T) {
+	writer := &countingWriter{}
+	log.SetOutput(writer)
+	defer log.SetOutput(os.Stderr)
+	SetLevel(LvlInfo)
+	subscription, cancel := Subscribe()
+	defer cancel()
+
+	when := time.Date(2019, 1, 26, 22, 57, 15, 0, time.Local)
+	entries := []Entry{
+		{Time: when, Level: LvlInfo, File: "/src/offline.go", Line: 18, Message: "first"},
+		{Time: when, Level: LvlDebug, File: "/src/offline.go", Line: 19, Message: "skipped"},
+		{Time: when, Level: LvlWarn, Worker: "conn-42", Message: "second"},
+	}
+	for i := 0; i < 1000; i++ {
+		entries = append(entries, Entry{Time: when, Level: LvlInfo, Message: fmt.Sprintf("entry %d", i)})
+	}
+	WriteBatch(entries)
+
+	output := writer.String()
+	if !strings.HasPrefix(output, "2019/01/26 22:57:15 offline.go:18: INFO - first\n"+
+		"2019/01/26 22:57:15 ???:0: [conn-42] WARN - second\n") {
+		t.Fatalf("Batch not written correctly: %.200s", output)
+	}
+	if strings.Count(output, "\n") != 1002 || writer.writes > 3 {
+		t.Fatalf("Expected 1002 entries in a few writes, got %d entries in %d writes",
+			strings.Count(output, "\n"), writer.writes)
+	}
+	if e := <-subscription; e.Message != "first" {
+		t.Fatalf("Handlers not called: %v", e)
+	}
+}